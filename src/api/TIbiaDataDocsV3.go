@@ -1,4 +1,4 @@
-package main
+package api
 
 // InformationV3 stores some API related data
 type InformationV3 struct {
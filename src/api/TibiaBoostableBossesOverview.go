@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"fmt"
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
 )
 
 // Child of BoostableBoss (used for list of boostable bosses and boosted boss section)
@@ -108,6 +109,11 @@ func TibiaBoostableBossesOverviewImpl(BoxContentHTML string) (*BoostableBossesOv
 		return nil, insideError
 	}
 
+	// an empty list means the layout of tibia.com changed (there are always boostable bosses)
+	if len(BoostableBossesData) == 0 {
+		return nil, validation.ErrorUpstreamUnknownLayout
+	}
+
 	// Build the data-blob
 	return &BoostableBossesOverviewResponse{
 		BoostableBossesContainer{
@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"encoding/json"
@@ -382,7 +382,8 @@ func TestEmptyName(t *testing.T) {
 		},
 	}
 
-	tibiaCharactersCharacter(c)
+	s := &webServer{}
+	s.tibiaCharactersCharacter(c)
 	assert.Equal(http.StatusBadRequest, w.Code)
 
 	var jerr OutInformation
@@ -412,7 +413,8 @@ func TestSmallName(t *testing.T) {
 		},
 	}
 
-	tibiaCharactersCharacter(c)
+	s := &webServer{}
+	s.tibiaCharactersCharacter(c)
 	assert.Equal(http.StatusBadRequest, w.Code)
 
 	var jerr OutInformation
@@ -440,7 +442,8 @@ func TestInvalidName(t *testing.T) {
 		},
 	}
 
-	tibiaCharactersCharacter(c)
+	s := &webServer{}
+	s.tibiaCharactersCharacter(c)
 	assert.Equal(http.StatusBadRequest, w.Code)
 
 	var jerr OutInformation
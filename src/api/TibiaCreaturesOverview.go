@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"fmt"
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
 )
 
 // Child of Creatures (used for list of creatures and boosted section)
@@ -113,6 +114,11 @@ func TibiaCreaturesOverviewImpl(BoxContentHTML string) (*CreaturesOverviewRespon
 		return nil, insideError
 	}
 
+	// an empty list means the layout of tibia.com changed (there are always creatures)
+	if len(CreaturesData) == 0 {
+		return nil, validation.ErrorUpstreamUnknownLayout
+	}
+
 	// Build the data-blob
 	return &CreaturesOverviewResponse{
 		CreaturesContainer{
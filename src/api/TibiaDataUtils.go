@@ -1,19 +1,26 @@
-package main
+package api
 
 import (
+	"bytes"
 	"html"
 	"io"
 	"log"
+	"mime"
 	"net/url"
 	"os"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
 	"golang.org/x/text/cases"
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/language"
 	"golang.org/x/text/unicode/norm"
 )
@@ -83,15 +90,17 @@ func TibiaDataStringWorldFormatToTitle(world string) string {
 }
 
 // TibiaDataQueryEscapeString func - encode string to be correct formatted
+// strings that can not be represented in the charset of tibia.com are escaped as UTF-8
+// use TibiaDataQueryEscape for user input
 func TibiaDataQueryEscapeString(data string) string {
-	// switching "+" to " "
-	data = strings.ReplaceAll(data, "+", " ")
-
-	// encoding string to latin-1
-	data, _ = TibiaDataConvertEncodingtoISO88591(data)
+	return tibiaDataDefaultCharset.QueryEscapeString(data)
+}
 
-	// returning with QueryEscape function
-	return url.QueryEscape(data)
+// TibiaDataQueryEscape func - encode string in the charset of tibia.com (ISO-8859-1) to be correct formatted
+// returns validation.ErrorStringCanNotBeEncoded if the string can not be represented in the charset
+// the handlers of a router use the charset its fetcher detected instead
+func TibiaDataQueryEscape(data string) (string, error) {
+	return tibiaDataDefaultCharset.QueryEscape(data)
 }
 
 // TibiaDataDate func
@@ -153,14 +162,135 @@ func RemoveHtmlTag(in string) string {
 	return in
 }
 
-// TibiaDataConvertEncodingtoISO88591 func - convert string from UTF-8 to latin1 (ISO 8859-1)
-func TibiaDataConvertEncodingtoISO88591(data string) (string, error) {
-	return charmap.ISO8859_1.NewEncoder().String(data)
+// tibiaDataDefaultCharset is the charset of tibia.com before a response says otherwise, it is never updated
+var tibiaDataDefaultCharset = newTibiaDataCharset()
+
+// tibiaDataCharset is the charset of the pages of tibia.com known to a fetcher, updated with every response
+// tibia.com decodes query strings in the same charset
+type tibiaDataCharset struct {
+	mu       sync.RWMutex
+	name     string
+	encoding encoding.Encoding
+}
+
+// newTibiaDataCharset func - creates a tibiaDataCharset with ISO-8859-1, the charset of tibia.com
+func newTibiaDataCharset() *tibiaDataCharset {
+	return &tibiaDataCharset{
+		name:     "iso-8859-1",
+		encoding: charmap.ISO8859_1,
+	}
+}
+
+// Get func - returns the name and encoding of the charset
+func (c *tibiaDataCharset) Get() (string, encoding.Encoding) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.name, c.encoding
+}
+
+// set func - changes the charset to name and enc
+func (c *tibiaDataCharset) set(name string, enc encoding.Encoding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if name != c.name {
+		log.Printf("[info] TibiaData API charset of tibia.com is %s", name)
+	}
+
+	c.name, c.encoding = name, enc
+}
+
+// QueryEscape func - encode string in the charset to be correct formatted
+// returns validation.ErrorStringCanNotBeEncoded if the string can not be represented in the charset
+func (c *tibiaDataCharset) QueryEscape(data string) (string, error) {
+	// switching "+" to " "
+	data = strings.ReplaceAll(data, "+", " ")
+
+	// encoding string to the charset of tibia.com (usually latin-1)
+	_, enc := c.Get()
+	data, err := enc.NewEncoder().String(data)
+	if err != nil {
+		return "", validation.ErrorStringCanNotBeEncoded
+	}
+
+	// returning with QueryEscape function
+	return url.QueryEscape(data), nil
 }
 
-// TibiaDataConvertEncodingtoUTF8 func - convert string from latin1 (ISO 8859-1) to UTF-8
-func TibiaDataConvertEncodingtoUTF8(data io.Reader) io.Reader {
-	return norm.NFKC.Reader(charmap.ISO8859_1.NewDecoder().Reader(data))
+// QueryEscapeString func - encode string in the charset to be correct formatted
+// strings that can not be represented in the charset are escaped as UTF-8
+func (c *tibiaDataCharset) QueryEscapeString(data string) string {
+	escaped, err := c.QueryEscape(data)
+	if err != nil {
+		return url.QueryEscape(strings.ReplaceAll(data, "+", " "))
+	}
+
+	return escaped
+}
+
+// tibiaDataCharsetMetaRegex matches <meta charset="..."> and <meta http-equiv="Content-Type" content="text/html; charset=...">
+var tibiaDataCharsetMetaRegex = regexp.MustCompile(`(?i)<meta[^>]+charset=["']?([\w-]+)`)
+
+// TibiaDataDetectCharset func - returns the charset of a page from the Content-Type header or else the meta tags of the html
+// returns an empty string if neither declares one
+func TibiaDataDetectCharset(contentType string, body []byte) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return strings.ToLower(params["charset"])
+	}
+
+	// the meta tags must be within the first 1024 bytes of the html
+	if len(body) > 1024 {
+		body = body[:1024]
+	}
+	if match := tibiaDataCharsetMetaRegex.FindSubmatch(body); match != nil {
+		return strings.ToLower(string(match[1]))
+	}
+
+	return ""
+}
+
+// tibiaDataEncoding func - returns the encoding of a charset name
+// ISO-8859-1 is not replaced with windows-1252 like browsers do, since tibia.com really uses latin-1
+func tibiaDataEncoding(name string) (encoding.Encoding, error) {
+	switch name {
+	case "iso-8859-1", "iso8859-1", "latin1", "l1":
+		return charmap.ISO8859_1, nil
+	case "utf-8", "utf8":
+		return unicode.UTF8, nil
+	}
+
+	return htmlindex.Get(name)
+}
+
+// TibiaDataConvertResponseToUTF8 func - convert a page of tibia.com from the charset it declares to UTF-8
+// pages without a known charset are converted from ISO-8859-1
+func TibiaDataConvertResponseToUTF8(contentType string, body []byte) io.Reader {
+	_, enc := tibiaDataResponseCharset(contentType, body)
+
+	return tibiaDataConvertToUTF8(enc, body)
+}
+
+// tibiaDataResponseCharset func - returns the name and encoding of the charset a page of tibia.com declares
+// pages without a known charset get ISO-8859-1
+func tibiaDataResponseCharset(contentType string, body []byte) (string, encoding.Encoding) {
+	name := TibiaDataDetectCharset(contentType, body)
+	if name == "" {
+		return tibiaDataDefaultCharset.Get()
+	}
+
+	enc, err := tibiaDataEncoding(name)
+	if err != nil {
+		log.Printf("[warning] TibiaData API unknown charset %q of tibia.com, using iso-8859-1", name)
+		return tibiaDataDefaultCharset.Get()
+	}
+
+	return name, enc
+}
+
+// tibiaDataConvertToUTF8 func - wraps body in a reader converting it from enc to UTF-8
+func tibiaDataConvertToUTF8(enc encoding.Encoding, body []byte) io.Reader {
+	return norm.NFKC.Reader(enc.NewDecoder().Reader(bytes.NewReader(body)))
 }
 
 // TibiaDataSanitizeEscapedString func - run unescape string on string
@@ -213,25 +343,25 @@ func getEnvAsBool(name string, defaultVal bool) bool {
 	return defaultVal
 }
 
-/*
+// getEnvAsInt func - read an environment variable into integer or return a default value
+func getEnvAsInt(name string, defaultVal int) int {
+	valueStr := getEnv(name, "")
+	if value, err := strconv.Atoi(valueStr); err == nil {
+		return value
+	}
+
+	return defaultVal
+}
+
 // getEnvAsFloat func - read an environment variable into a float64 or return default value
 func getEnvAsFloat(name string, defaultVal float64) float64 {
 	valStr := getEnv(name, "")
 	if val, err := strconv.ParseFloat(valStr, 64); err == nil {
 		return val
 	}
-	return defaultVal
-}
 
-// getEnvAsInt func - read an environment variable into integer or return a default value
-func getEnvAsInt(name string, defaultVal int) int {
-	valueStr := getEnv(name, "")
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
-	}
 	return defaultVal
 }
-*/
 
 // TibiaDataConvertValuesWithK func - convert price strings that contain k, kk or more to 3x0
 func TibiaDataConvertValuesWithK(data string) int {
@@ -1,9 +1,13 @@
-package main
+package api
 
 import (
+	"io"
 	"testing"
 
+	"github.com/TibiaData/tibiadata-api-go/src/static"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/charmap"
 )
 
 func TestTibiaCETDateFormat(t *testing.T) {
@@ -26,6 +30,9 @@ func TestEnvFunctions(t *testing.T) {
 	assert.Equal("default", getEnv("TIBIADATA_ENV", "default"))
 
 	assert.False(false, getEnvAsBool("TIBIADATA_ENV", true))
+
+	assert.Equal(16, getEnvAsInt("TIBIADATA_ENV", 16))
+	assert.Equal(2.5, getEnvAsFloat("TIBIADATA_ENV", 2.5))
 }
 
 func TestTibiaDataVocationValidator(t *testing.T) {
@@ -110,6 +117,72 @@ func TestEscaper(t *testing.T) {
 	assert.Equal(sanitizedStrThree, "g%F3d")
 }
 
+func TestEscaperCharset(t *testing.T) {
+	assert := assert.New(t)
+
+	charset := newTibiaDataCharset()
+
+	// names that can not be represented in latin-1 are rejected
+	_, err := charset.QueryEscape("Torbjörn Ж")
+	assert.Equal(validation.ErrorStringCanNotBeEncoded, err)
+
+	// the charset of the last response is used for query strings
+	name, enc := tibiaDataResponseCharset("text/html; charset=UTF-8", []byte("<p>Torbjörn</p>"))
+	assert.Equal("utf-8", name)
+	charset.set(name, enc)
+
+	data, err := io.ReadAll(tibiaDataConvertToUTF8(enc, []byte("<p>Torbjörn</p>")))
+	assert.Nil(err)
+	assert.Equal("<p>Torbjörn</p>", string(data))
+
+	escaped, err := charset.QueryEscape("Torbjörn Ж")
+	assert.Nil(err)
+	assert.Equal("Torbj%C3%B6rn+%D0%96", escaped)
+
+	// other charsets are not changed
+	_, err = TibiaDataQueryEscape("Torbjörn Ж")
+	assert.Equal(validation.ErrorStringCanNotBeEncoded, err)
+
+	// pages without a charset are latin-1
+	name, enc = tibiaDataResponseCharset("text/html", []byte("<p>Torbj\xf6rn</p>"))
+	assert.Equal("iso-8859-1", name)
+	assert.Equal(charmap.ISO8859_1, enc)
+}
+
+func TestDetectCharset(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("iso-8859-1", TibiaDataDetectCharset("text/html; charset=ISO-8859-1", nil))
+	assert.Equal("utf-8", TibiaDataDetectCharset("text/html", []byte(`<html><head><meta charset="utf-8"></head></html>`)))
+	assert.Equal("windows-1252", TibiaDataDetectCharset("", []byte(`<meta http-equiv="Content-Type" content="text/html; charset=windows-1252">`)))
+	assert.Equal("", TibiaDataDetectCharset("text/html", []byte(`<html></html>`)))
+
+	enc, err := tibiaDataEncoding("iso-8859-1")
+	assert.Nil(err)
+	assert.Equal(charmap.ISO8859_1, enc)
+
+	_, err = tibiaDataEncoding("klingon")
+	assert.NotNil(err)
+}
+
+func TestConvertResponseToUTF8(t *testing.T) {
+	assert := assert.New(t)
+
+	file, err := static.TestFiles.ReadFile("testdata/characters/Torbjörn.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fixture round-trips through the latin-1 page of tibia.com
+	data, err := io.ReadAll(TibiaDataConvertResponseToUTF8("text/html; charset=ISO-8859-1", static.EncodeLatin1(file)))
+	assert.Nil(err)
+	assert.Contains(string(data), "Torbjörn")
+
+	escaped, err := TibiaDataQueryEscape("Torbjörn")
+	assert.Nil(err)
+	assert.Equal("Torbj%F6rn", escaped)
+}
+
 func TestDateParser(t *testing.T) {
 	const str = "Mar 09 2022"
 
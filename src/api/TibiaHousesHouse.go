@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"fmt"
@@ -73,6 +73,11 @@ var (
 
 // TibiaHousesHouse func
 func TibiaHousesHouseImpl(houseid int, BoxContentHTML string) (*HouseResponse, error) {
+	return tibiaHousesHouseImpl(validation.Default(), houseid, BoxContentHTML)
+}
+
+// tibiaHousesHouseImpl func - parses the house with the town and type of it in validator
+func tibiaHousesHouseImpl(validator *validation.Validator, houseid int, BoxContentHTML string) (*HouseResponse, error) {
 	// Creating empty vars
 	var HouseData House
 
@@ -95,7 +100,7 @@ func TibiaHousesHouseImpl(houseid int, BoxContentHTML string) (*HouseResponse, e
 		HouseData.Houseid = houseid
 		HouseData.World = subma1[0][8]
 
-		rawHouse, err := validation.GetHouseRaw(HouseData.Houseid)
+		rawHouse, err := validator.GetHouseRaw(HouseData.Houseid)
 		if err != nil {
 			return nil, err
 		}
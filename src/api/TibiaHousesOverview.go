@@ -1,13 +1,13 @@
-package main
+package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/gin-gonic/gin"
 	"github.com/go-resty/resty/v2"
 )
 
@@ -49,7 +49,12 @@ var (
 )
 
 // TibiaHousesOverview func
-func TibiaHousesOverviewImpl(c *gin.Context, world string, town string, htmlDataCollector func(TibiaDataRequestStruct) (string, error)) (*HousesOverviewResponse, error) {
+func TibiaHousesOverviewImpl(ctx context.Context, world string, town string, fetcher Fetcher) (*HousesOverviewResponse, error) {
+	return tibiaHousesOverviewImpl(ctx, world, town, fetcher, tibiaDataDefaultCharset)
+}
+
+// tibiaHousesOverviewImpl func - TibiaHousesOverviewImpl with the queries encoded in charset
+func tibiaHousesOverviewImpl(ctx context.Context, world string, town string, fetcher Fetcher, charset *tibiaDataCharset) (*HousesOverviewResponse, error) {
 	var (
 		// Creating empty vars
 		HouseData, GuildhallData []HousesHouse
@@ -60,9 +65,9 @@ func TibiaHousesOverviewImpl(c *gin.Context, world string, town string, htmlData
 
 	// running over the FansiteTypes array
 	for _, HouseType := range HouseTypes {
-		houses, err := makeHouseRequest(HouseType, world, town, htmlDataCollector)
+		houses, err := makeHouseRequest(ctx, HouseType, world, town, fetcher, charset)
 		if err != nil {
-			return nil, fmt.Errorf("[error] TibiaHousesOverviewImpl failed at makeHouseRequest, type: %s, err: %s", HouseType, err)
+			return nil, fmt.Errorf("[error] TibiaHousesOverviewImpl failed at makeHouseRequest, type: %s, err: %w", HouseType, err)
 		}
 
 		switch HouseType {
@@ -91,16 +96,16 @@ func TibiaHousesOverviewImpl(c *gin.Context, world string, town string, htmlData
 	}, nil
 }
 
-func makeHouseRequest(HouseType, world, town string, htmlDataCollector func(TibiaDataRequestStruct) (string, error)) ([]HousesHouse, error) {
+func makeHouseRequest(ctx context.Context, HouseType, world, town string, fetcher Fetcher, charset *tibiaDataCharset) ([]HousesHouse, error) {
 	// Creating an empty var
 	var output []HousesHouse
 
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=houses&world=" + TibiaDataQueryEscapeString(world) + "&town=" + TibiaDataQueryEscapeString(town) + "&type=" + TibiaDataQueryEscapeString(HouseType),
+		URL:    "https://www.tibia.com/community/?subtopic=houses&world=" + charset.QueryEscapeString(world) + "&town=" + charset.QueryEscapeString(town) + "&type=" + charset.QueryEscapeString(HouseType),
 	}
 
-	BoxContentHTML, err := htmlDataCollector(tibiadataRequest)
+	BoxContentHTML, err := fetcher.Fetch(ctx, tibiadataRequest)
 	// return error (e.g. for maintenance mode)
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
-package main
+package api
 
 import (
+	"context"
 	"io"
 	"strings"
 	"testing"
@@ -33,16 +34,16 @@ func TestAnticaThaisHousesOverview(t *testing.T) {
 	}
 
 	housesJson, err := TibiaHousesOverviewImpl(
-		nil,
+		context.Background(),
 		"Antica",
 		"Thais",
-		func(request TibiaDataRequestStruct) (string, error) {
+		FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
 			if strings.Contains(request.URL, "guildhalls") {
 				return string(guildData), nil
 			}
 
 			return string(houseData), nil
-		})
+		}))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -117,16 +118,16 @@ func TestPremiaFarmineHousesOverview(t *testing.T) {
 	}
 
 	housesJson, err := TibiaHousesOverviewImpl(
-		nil,
+		context.Background(),
 		"Premia",
 		"Farmine",
-		func(request TibiaDataRequestStruct) (string, error) {
+		FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
 			if strings.Contains(request.URL, "guildhalls") {
 				return string(guildData), nil
 			}
 
 			return string(houseData), nil
-		})
+		}))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -174,16 +175,16 @@ func TestPremiaEdronHousesOverview(t *testing.T) {
 	}
 
 	housesJson, err := TibiaHousesOverviewImpl(
-		nil,
+		context.Background(),
 		"Premia",
 		"Edron",
-		func(request TibiaDataRequestStruct) (string, error) {
+		FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
 			if strings.Contains(request.URL, "guildhalls") {
 				return string(guildData), nil
 			}
 
 			return string(houseData), nil
-		})
+		}))
 	if err != nil {
 		t.Fatal(err)
 	}
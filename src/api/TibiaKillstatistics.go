@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"fmt"
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
 )
 
 // Child of KillStatistics
@@ -74,6 +75,11 @@ func TibiaKillstatisticsImpl(world string, BoxContentHTML string) (*KillStatisti
 		})
 	})
 
+	// an empty list means the layout of tibia.com changed (there are always killstatistics)
+	if len(KillStatisticsData) == 0 {
+		return nil, validation.ErrorUpstreamUnknownLayout
+	}
+
 	//
 	// Build the data-blob
 	return &KillStatisticsResponse{
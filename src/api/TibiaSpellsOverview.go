@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"fmt"
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
 )
 
 // Child of Spells
@@ -108,6 +109,11 @@ func TibiaSpellsOverviewImpl(vocationName string, BoxContentHTML string) (*Spell
 		SpellsData = append(SpellsData, spellBuilder)
 	})
 
+	// an empty list means the layout of tibia.com changed (every vocation has spells)
+	if len(SpellsData) == 0 {
+		return nil, validation.ErrorUpstreamUnknownLayout
+	}
+
 	// adding readable SpellsVocationFilter field
 	if vocationName == "" {
 		vocationName = "all"
@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"fmt"
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
 )
 
 // Child of Worlds
@@ -193,6 +194,11 @@ func TibiaWorldsOverviewImpl(BoxContentHTML string) (*WorldsOverviewResponse, er
 		return nil, insideError
 	}
 
+	// an empty list means the layout of tibia.com changed (there are always regular worlds)
+	if len(RegularWorldsData) == 0 {
+		return nil, validation.ErrorUpstreamUnknownLayout
+	}
+
 	//
 	// Build the data-blob
 	return &WorldsOverviewResponse{
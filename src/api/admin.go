@@ -0,0 +1,139 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminStatusResponse is the state of the running API
+type AdminStatusResponse struct {
+	Debug            bool   `json:"debug"`              // Whether the debug mode is enabled.
+	MappingSha256Sum string `json:"mapping_sha256_sum"` // The sha256 sum of the tibiamapping data in use.
+	RuntimeStatus
+	Information Information `json:"information"`
+}
+
+// AdminCachePurgeResponse is the result of a cache purge
+type AdminCachePurgeResponse struct {
+	Purged      int         `json:"purged"`       // The number of removed cache entries.
+	PurgedStale int         `json:"purged_stale"` // The number of removed stale entries.
+	Information Information `json:"information"`
+}
+
+// AdminMappingResponse is the tibiamapping data in use after a reload
+type AdminMappingResponse struct {
+	Sha256Sum   string      `json:"sha256_sum"` // The sha256 sum of the tibiamapping data.
+	Sha512Sum   string      `json:"sha512_sum"` // The sha512 sum of the tibiamapping data.
+	Information Information `json:"information"`
+}
+
+// AdminDebugResponse is the debug mode after a change
+type AdminDebugResponse struct {
+	Debug       bool        `json:"debug"` // Whether the debug mode is enabled.
+	Information Information `json:"information"`
+}
+
+// adminInformation func - returns the Information of a successful admin response
+func adminInformation() Information {
+	return Information{
+		APIDetails: TibiaDataAPIDetails,
+		Timestamp:  TibiaDataDatetime(""),
+		Status: Status{
+			HTTPCode: http.StatusOK,
+		},
+	}
+}
+
+// adminStatusHandler returns the state of tibia.com, the proxy pool and the background jobs
+func (s *webServer) adminStatusHandler(c *gin.Context) {
+	sha256, _ := s.validation().GetSha256Sum()
+
+	c.JSON(http.StatusOK, AdminStatusResponse{
+		Debug:            s.debugMode().Load(),
+		MappingSha256Sum: sha256,
+		RuntimeStatus:    s.runtimeStatus(),
+		Information:      adminInformation(),
+	})
+}
+
+// adminCachePurgeHandler removes the cache entries of the key or prefix query parameter
+// the keys are the requests to tibia.com, like "GET https://www.tibia.com/community/?subtopic=worlds"
+// an empty prefix removes all entries
+func (s *webServer) adminCachePurgeHandler(c *gin.Context) {
+	key, isKey := c.GetQuery("key")
+	prefix, isPrefix := c.GetQuery("prefix")
+	if !isKey && !isPrefix {
+		TibiaDataErrorHandler(c, validation.ErrorAdminCachePurgeTargetMissing, http.StatusBadRequest)
+		return
+	}
+
+	purge := func(cache ResponseCache) (int, error) {
+		if cache == nil {
+			return 0, nil
+		}
+		if isKey {
+			return cache.Delete(key)
+		}
+		return cache.DeletePrefix(prefix)
+	}
+
+	var output AdminCachePurgeResponse
+	var err error
+
+	output.Purged, err = purge(s.cache)
+	if err != nil {
+		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	output.PurgedStale, err = purge(s.stale)
+	if err != nil {
+		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[info] TibiaData API admin: purged %d cache and %d stale entries (key: %q, prefix: %q)", output.Purged, output.PurgedStale, key, prefix)
+
+	output.Information = adminInformation()
+	c.JSON(http.StatusOK, output)
+}
+
+// adminMappingReloadHandler loads the tibiamapping data of the router again, the current data is kept if it fails
+func (s *webServer) adminMappingReloadHandler(c *gin.Context) {
+	if err := s.validation().Reload(TibiaDataUserAgent); err != nil {
+		log.Printf("[warning] TibiaData API admin: tibiamapping reload failed: %s", err)
+		TibiaDataErrorHandler(c, err, http.StatusBadGateway)
+		return
+	}
+
+	sha256, _ := s.validation().GetSha256Sum()
+	sha512, _ := s.validation().GetSha512Sum()
+	log.Printf("[info] TibiaData API admin: tibiamapping reloaded (sha256: %s)", sha256)
+
+	c.JSON(http.StatusOK, AdminMappingResponse{
+		Sha256Sum:   sha256,
+		Sha512Sum:   sha512,
+		Information: adminInformation(),
+	})
+}
+
+// adminDebugHandler enables or disables the debug mode of the router with the enabled query parameter
+func (s *webServer) adminDebugHandler(c *gin.Context) {
+	enabled, err := strconv.ParseBool(c.Query("enabled"))
+	if err != nil {
+		TibiaDataErrorHandler(c, validation.ErrorAdminDebugValueInvalid, http.StatusBadRequest)
+		return
+	}
+
+	s.debugMode().Store(enabled)
+	log.Printf("[info] TibiaData API admin: debug-mode set to %t", enabled)
+
+	c.JSON(http.StatusOK, AdminDebugResponse{
+		Debug:       enabled,
+		Information: adminInformation(),
+	})
+}
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"context"
+	sha256sum "crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/faketibia"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAdminTestRouter func - returns a router with the admin routes of s and an admin key
+func newAdminTestRouter(t *testing.T, s *webServer) (*gin.Engine, func(method, path string) *httptest.ResponseRecorder) {
+	store, err := newAPIKeyStore([]APIKey{{Name: "admin", Key: "admin-key", Admin: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.apiKeys = store
+
+	router := gin.New()
+	admin := router.Group("/admin", store.AdminMiddleware())
+	admin.GET("/status", s.adminStatusHandler)
+	admin.DELETE("/cache", s.adminCachePurgeHandler)
+	admin.POST("/mapping/reload", s.adminMappingReloadHandler)
+	admin.PUT("/debug", s.adminDebugHandler)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, nil)
+		request.Header.Set("X-API-Key", "admin-key")
+		router.ServeHTTP(w, request)
+		return w
+	}
+
+	return router, serve
+}
+
+func TestAdminCachePurge(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &webServer{
+		cache: newMemoryCache(0, 0),
+		stale: newMemoryCache(0, 0),
+	}
+	for _, key := range []string{"GET https://www.tibia.com/community/?subtopic=worlds", "GET https://www.tibia.com/community/?subtopic=worlds&world=Antica", "GET https://www.tibia.com/library/?subtopic=creatures"} {
+		s.cache.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+		s.stale.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+	}
+
+	router, serve := newAdminTestRouter(t, s)
+
+	// the admin routes need an admin key
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/cache?prefix=", nil))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal(3, s.cache.Stats().Entries)
+
+	w = serve(http.MethodDelete, "/admin/cache")
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(w.Body.String(), `"error":15007`)
+
+	w = serve(http.MethodDelete, "/admin/cache?key=GET+https://www.tibia.com/community/%3Fsubtopic%3Dworlds")
+	assert.Equal(http.StatusOK, w.Code)
+
+	var output AdminCachePurgeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(1, output.Purged)
+	assert.Equal(1, output.PurgedStale)
+
+	w = serve(http.MethodDelete, "/admin/cache?prefix=GET+https://www.tibia.com/library/")
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(1, output.Purged)
+	assert.Equal(1, s.cache.Stats().Entries)
+
+	// an empty prefix purges all entries
+	w = serve(http.MethodDelete, "/admin/cache?prefix=")
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(1, output.Purged)
+	assert.Equal(1, output.PurgedStale)
+	assert.Equal(0, s.stale.Stats().Entries)
+}
+
+func TestAdminCachePurgeStale(t *testing.T) {
+	assert := assert.New(t)
+
+	config := defaultConfig()
+	config.Cache.Backend = "memory"
+	config.Cache.StaleIfError = true
+	config.APIKeys.Keys = []APIKey{{Name: "admin", Key: "admin-key", Admin: true}}
+
+	router, err := NewRouter(RouterOptions{
+		Config: &config,
+		Fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			return "", nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := router.server
+	for _, key := range []string{"GET https://www.tibia.com/community/?subtopic=worlds", "GET https://www.tibia.com/library/?subtopic=creatures"} {
+		s.cache.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+		s.storeStale(key, CacheEntry{Data: []byte("data")})
+	}
+
+	// the cache and the last successful responses are purged and counted on their own
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodDelete, "/admin/cache?prefix=", nil)
+	request.Header.Set("X-API-Key", "admin-key")
+	router.ServeHTTP(w, request)
+	assert.Equal(http.StatusOK, w.Code)
+
+	var output AdminCachePurgeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(2, output.Purged)
+	assert.Equal(2, output.PurgedStale)
+}
+
+func TestAdminDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	router, err := NewRouter(RouterOptions{
+		Fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			return "", nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without API keys the admin endpoints do not exist
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/status", nil))
+	assert.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestAdminDebug(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &webServer{debug: new(atomic.Bool)}
+	_, serve := newAdminTestRouter(t, s)
+
+	// only the debug mode of the router is changed
+	w := serve(http.MethodPut, "/admin/debug?enabled=true")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.True(s.debug.Load())
+	assert.False(TibiaDataDebug.Load())
+
+	w = serve(http.MethodGet, "/admin/status")
+	assert.Equal(http.StatusOK, w.Code)
+
+	var status AdminStatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(status.Debug)
+
+	w = serve(http.MethodPut, "/admin/debug?enabled=false")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.False(s.debug.Load())
+
+	w = serve(http.MethodPut, "/admin/debug?enabled=maybe")
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(w.Body.String(), `"error":15008`)
+	assert.False(s.debug.Load())
+}
+
+func TestAdminStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	jobs, err := parseWarmerJobs("@hourly /v4/worlds")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &webServer{
+		cache:   newMemoryCache(0, 0),
+		circuit: newUpstreamCircuit(3, time.Minute, time.Minute),
+		warmer:  newCacheWarmer(http.NotFoundHandler(), jobs),
+	}
+	_, serve := newAdminTestRouter(t, s)
+
+	w := serve(http.MethodGet, "/admin/status")
+	assert.Equal(http.StatusOK, w.Code)
+
+	var status AdminStatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+
+	sha256, _ := validation.GetSha256Sum()
+	assert.Equal(sha256, status.MappingSha256Sum)
+	assert.Equal("memory", status.Cache.Backend)
+	assert.NotNil(status.Upstream)
+	assert.Len(status.Warmer, 1)
+	assert.Empty(status.Proxies)
+}
+
+func TestAdminMappingReload(t *testing.T) {
+	assert := assert.New(t)
+
+	_, serve := newAdminTestRouter(t, &webServer{})
+
+	sha256, err := validation.GetSha256Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the current data is kept if the assets can not be loaded
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	t.Setenv("TIBIADATA_ASSETS_URL", unreachable.URL)
+
+	w := serve(http.MethodPost, "/admin/mapping/reload")
+	assert.Equal(http.StatusBadGateway, w.Code)
+
+	current, _ := validation.GetSha256Sum()
+	assert.Equal(sha256, current)
+
+	// the mapping is served like assets.tibiadata.com by faketibia
+	fake, err := faketibia.NewFromTestFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assets := httptest.NewServer(fake)
+	defer assets.Close()
+	t.Setenv("TIBIADATA_ASSETS_URL", assets.URL+"/")
+
+	var mapping map[string]interface{}
+	if err := json.Unmarshal(fetchAsset(t, assets.URL+"/data.min.json"), &mapping); err != nil {
+		t.Fatal(err)
+	}
+	worlds := mapping["worlds"]
+
+	// a changed mapping is loaded
+	mapping["worlds"] = append(worlds.([]interface{}), "Reloadia")
+	changed, _ := json.Marshal(mapping)
+	fake.SetMapping(changed)
+
+	w = serve(http.MethodPost, "/admin/mapping/reload")
+	assert.Equal(http.StatusOK, w.Code)
+
+	var output AdminMappingResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(fmt.Sprintf("%x", sha256sum.Sum256(changed)), output.Sha256Sum)
+	assert.NotEqual(sha256, output.Sha256Sum)
+
+	exists, _ := validation.WorldExists("Reloadia")
+	assert.True(exists)
+
+	// the data of the other tests is loaded again
+	mapping["worlds"] = worlds
+	original, _ := json.Marshal(mapping)
+	fake.SetMapping(original)
+
+	w = serve(http.MethodPost, "/admin/mapping/reload")
+	assert.Equal(http.StatusOK, w.Code)
+
+	exists, _ = validation.WorldExists("Reloadia")
+	assert.False(exists)
+}
+
+// fetchAsset func - returns the body of a file of the assets server
+func fetchAsset(t *testing.T, url string) []byte {
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
@@ -0,0 +1,124 @@
+// Package api is the TibiaData API: the handlers of the endpoints, the router and the webserver
+// NewRouter returns the API as http.Handler, so it can be mounted in other servers
+package api
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+)
+
+var (
+	// TibiaDataDefaultVoc - default vocation when not specified in request
+	TibiaDataDefaultVoc string = "all"
+
+	// TibiaData app flags for running
+	TibiaDataAPIversion int = 4
+
+	// TibiaDataDebug logs much more details outside of the routers (set by DEBUG_MODE)
+	// every router has a debug mode of its own, it can be toggled at runtime on /admin/debug
+	TibiaDataDebug atomic.Bool
+
+	// TibiaData app settings
+	TibiaDataHost string // set through the host setting (env TIBIADATA_HOST)
+
+	// TibiaData app details set to release/build on GitHub
+	TibiaDataBuildRelease = "unknown"     // will be set by GitHub Actions (to release number)
+	TibiaDataBuildBuilder = "manual"      // will be set by GitHub Actions
+	TibiaDataBuildCommit  = "-"           // will be set by GitHub Actions (to git commit)
+	TibiaDataBuildEdition = "open-source" //
+
+	// TibiaDataAPIDetails containing information from build
+	TibiaDataAPIDetails = APIDetails{
+		Version: TibiaDataAPIversion,
+		Release: TibiaDataBuildRelease,
+		Commit:  TibiaDataBuildCommit,
+	}
+)
+
+func init() {
+	// Generating TibiaDataUserAgent with TibiaDataUserAgentGenerator function
+	TibiaDataUserAgent = TibiaDataUserAgentGenerator(TibiaDataAPIversion)
+}
+
+// TibiaDataInitValidator loads the tibiamapping data used to validate the requests
+// it is called by NewRouter, calling it again once the data is loaded does nothing
+func TibiaDataInitValidator() error {
+	return tibiaDataInitValidator(validation.Default())
+}
+
+// tibiaDataInitValidator func - loads the tibiamapping data into validator unless it is already loaded
+func tibiaDataInitValidator(validator *validation.Validator) error {
+	err := validator.Initiate(TibiaDataUserAgent)
+	if errors.Is(err, validation.ErrorAlreadyRunning) {
+		return nil
+	}
+
+	return err
+}
+
+// TibiaDataInitializer set the background for the webserver
+func TibiaDataInitializer(config *Config) {
+	// Setting TibiaDataBuildEdition
+	if config.Edition != "" {
+		TibiaDataBuildEdition = config.Edition
+	}
+
+	// Adding information of host
+	if config.Host != "" {
+		TibiaDataHost = "+https://" + config.Host
+	}
+
+	// Logging the proxy of tibia.com (used by the routers created with config)
+	if config.Upstream.Proxy != "" {
+		log.Printf("[info] TibiaData API proxy: %s", config.Upstream.proxyDomain())
+	}
+
+	// Logging build information
+	log.Printf("[info] TibiaData API release: %s", TibiaDataBuildRelease)
+	log.Printf("[info] TibiaData API build: %s", TibiaDataBuildBuilder)
+	log.Printf("[info] TibiaData API commit: %s", TibiaDataBuildCommit)
+	log.Printf("[info] TibiaData API edition: %s", TibiaDataBuildEdition)
+
+	// Setting tibiadata-application to log much less if debug (DEBUG_MODE) is false (default is false)
+	if !config.Debug {
+		log.Printf("[info] TibiaData API debug-mode: disabled")
+	} else {
+		// Setting debug to true for more logging
+		TibiaDataDebug.Store(true)
+		log.Printf("[info] TibiaData API debug-mode: enabled")
+
+		// Logging user-agent string
+		log.Printf("[debug] TIbiaData API User-Agent: %s", TibiaDataUserAgent)
+	}
+
+	// Run some functions that are empty but required for documentation to be done
+	_ = tibiaNewslistArchive()
+	_ = tibiaNewslistArchiveDays()
+	_ = tibiaNewslistLatest()
+
+	// Run functions for v3 documentation to work
+	_ = tibiaBoostableBossesV3()
+	_ = tibiaCharactersCharacterV3()
+	_ = tibiaCreaturesOverviewV3()
+	_ = tibiaCreaturesCreatureV3()
+	_ = tibiaFansitesV3()
+	_ = tibiaGuildsGuildV3()
+	_ = tibiaGuildsOverviewV3()
+	_ = tibiaHighscoresV3()
+	_ = tibiaHousesHouseV3()
+	_ = tibiaHousesOverviewV3()
+	_ = tibiaKillstatisticsV3()
+	_ = tibiaNewslistArchiveV3()
+	_ = tibiaNewslistArchiveDaysV3()
+	_ = tibiaNewslistLatestV3()
+	_ = tibiaNewslistV3()
+	_ = tibiaNewsV3()
+	_ = tibiaSpellsOverviewV3()
+	_ = tibiaSpellsSpellV3()
+	_ = tibiaWorldsOverviewV3()
+	_ = tibiaWorldsWorldV3()
+
+}
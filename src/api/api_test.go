@@ -0,0 +1,43 @@
+package api
+
+import (
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/TibiaData/tibiadata-api-go/src/faketibia"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain func - loads the tibiamapping data used by the validation of the handlers
+// the data is built from the static test files by faketibia, so the tests do not depend on assets.tibiadata.com
+func TestMain(m *testing.M) {
+	fake, err := faketibia.NewFromTestFiles()
+	if err != nil {
+		log.Fatalf("[error] TibiaData API faketibia: %s", err)
+	}
+
+	assets := httptest.NewServer(fake)
+	os.Setenv("TIBIADATA_ASSETS_URL", assets.URL+"/")
+
+	err = TibiaDataInitValidator()
+	os.Unsetenv("TIBIADATA_ASSETS_URL")
+	assets.Close()
+	if err != nil {
+		log.Fatalf("[error] TibiaData API tibiamapping: %s", err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestTibiaDataInitValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	// the data is only loaded once
+	assert.Nil(TibiaDataInitValidator())
+
+	assert.Equal(TibiaDataAPIversion, TibiaDataAPIDetails.Version)
+	assert.Equal(TibiaDataBuildRelease, TibiaDataAPIDetails.Release)
+	assert.Equal(TibiaDataBuildCommit, TibiaDataAPIDetails.Commit)
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKey is the configuration of an API key
+type APIKey struct {
+	Name              string   `json:"name"`                          // The name of the team or client using the key.
+	Key               string   `json:"key"`                           // The key sent in the X-API-Key or Authorization: Bearer header.
+	RequestsPerMinute int      `json:"requests_per_minute,omitempty"` // The requests allowed per minute (0 for no limit).
+	RequestsPerDay    int      `json:"requests_per_day,omitempty"`    // The requests allowed per day (0 for no limit).
+	Routes            []string `json:"routes,omitempty"`              // The route groups the key may access, like /v4/highscores (empty for all).
+	Admin             bool     `json:"admin,omitempty"`               // Whether the key may access the admin endpoints.
+}
+
+// APIKeyUsage is the usage of an API key shown on the admin endpoint
+type APIKeyUsage struct {
+	Name              string   `json:"name"`
+	RequestsPerMinute int      `json:"requests_per_minute"`
+	RequestsPerDay    int      `json:"requests_per_day"`
+	Routes            []string `json:"routes,omitempty"`
+	Requests          uint64   `json:"requests"`            // The number of accepted requests.
+	Rejected          uint64   `json:"rejected"`            // The number of requests rejected by the quotas or routes.
+	MinuteRequests    int      `json:"minute_requests"`     // The accepted requests of the current minute.
+	DayRequests       int      `json:"day_requests"`        // The accepted requests of the current day (UTC).
+	LastUsed          string   `json:"last_used,omitempty"` // When the key was used the last time.
+}
+
+// APIKeysUsageResponse is the response of the API keys admin endpoint
+type APIKeysUsageResponse struct {
+	APIKeys     []APIKeyUsage `json:"api_keys"`
+	Information Information   `json:"information"`
+}
+
+// apiKeyState holds the counters of an API key
+type apiKeyState struct {
+	APIKey
+
+	minute, day                 time.Time // the start of the current quota windows
+	minuteRequests, dayRequests int
+	requests, rejected          uint64
+	lastUsed                    time.Time
+}
+
+// apiKeyStore authenticates requests by API key and enforces the quotas of the keys
+type apiKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]*apiKeyState // by the sha256 sum of the key
+	now  func() time.Time
+
+	basePath string // the path the routes are mounted under, the routes of the keys are relative to it
+}
+
+// newAPIKeyStore func - creates an apiKeyStore of keys
+func newAPIKeyStore(keys []APIKey) (*apiKeyStore, error) {
+	store := &apiKeyStore{
+		keys: make(map[string]*apiKeyState, len(keys)),
+		now:  time.Now,
+	}
+
+	for _, key := range keys {
+		switch {
+		case key.Name == "":
+			return nil, errors.New("api key without name")
+		case key.Key == "":
+			return nil, fmt.Errorf("api key %s has no key", key.Name)
+		case key.RequestsPerMinute < 0 || key.RequestsPerDay < 0:
+			return nil, fmt.Errorf("api key %s has a negative quota", key.Name)
+		}
+
+		hash := apiKeyHash(key.Key)
+		if _, ok := store.keys[hash]; ok {
+			return nil, fmt.Errorf("api key %s is used twice", key.Name)
+		}
+
+		store.keys[hash] = &apiKeyState{APIKey: key}
+	}
+
+	return store, nil
+}
+
+// loadAPIKeys func - reads the API keys of a JSON file
+// it contains an array of APIKey, for example: [{"name":"team-a","key":"secret","requests_per_minute":60,"routes":["/v4/highscores"]}]
+func loadAPIKeys(fileName string) ([]APIKey, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	return keys, nil
+}
+
+// apiKeyHash func - returns the sha256 sum of key, so the keys are not kept in plain text
+func apiKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyFromRequest func - returns the API key of the X-API-Key or Authorization: Bearer header
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+
+	if key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(key)
+	}
+
+	return ""
+}
+
+// allowsRoute func - reports whether the key may access route
+func (k *APIKey) allowsRoute(route string) bool {
+	if len(k.Routes) == 0 {
+		return true
+	}
+
+	for _, group := range k.Routes {
+		group = strings.TrimSuffix(group, "/")
+		if route == group || strings.HasPrefix(route, group+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// take func - counts a request of the key if its quotas allow it
+// returns how long to wait for the next window and the quota error otherwise
+func (s *apiKeyStore) take(state *apiKeyState) (time.Duration, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	minute := now.Truncate(time.Minute)
+	if !state.minute.Equal(minute) {
+		state.minute, state.minuteRequests = minute, 0
+	}
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !state.day.Equal(day) {
+		state.day, state.dayRequests = day, 0
+	}
+
+	if state.RequestsPerDay > 0 && state.dayRequests >= state.RequestsPerDay {
+		state.rejected++
+		return day.Add(24 * time.Hour).Sub(now), validation.ErrorAPIKeyDailyQuotaExceeded
+	}
+
+	if state.RequestsPerMinute > 0 && state.minuteRequests >= state.RequestsPerMinute {
+		state.rejected++
+		return minute.Add(time.Minute).Sub(now), validation.ErrorAPIKeyMinuteQuotaExceeded
+	}
+
+	state.minuteRequests++
+	state.dayRequests++
+	state.requests++
+	state.lastUsed = now
+
+	return 0, nil
+}
+
+// reject func - counts a rejected request of the key
+func (s *apiKeyStore) reject(state *apiKeyState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.rejected++
+}
+
+// authenticate func - returns the state of the key of the request or aborts it
+func (s *apiKeyStore) authenticate(c *gin.Context) (*apiKeyState, bool) {
+	key := apiKeyFromRequest(c)
+	if key == "" {
+		c.Header("WWW-Authenticate", `Bearer realm="TibiaData API"`)
+		TibiaDataErrorHandler(c, validation.ErrorAPIKeyRequired, http.StatusUnauthorized)
+		c.Abort()
+		return nil, false
+	}
+
+	state, ok := s.keys[apiKeyHash(key)]
+	if !ok {
+		c.Header("WWW-Authenticate", `Bearer realm="TibiaData API", error="invalid_token"`)
+		TibiaDataErrorHandler(c, validation.ErrorAPIKeyInvalid, http.StatusUnauthorized)
+		c.Abort()
+		return nil, false
+	}
+
+	return state, true
+}
+
+// Middleware func - returns a gin middleware requiring an API key with access to the route and quota left
+// requests of the cache warmer do not need a key
+func (s *apiKeyStore) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isCacheRefresh(c.Request.Context()) {
+			c.Next()
+			return
+		}
+
+		state, ok := s.authenticate(c)
+		if !ok {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		route = strings.TrimPrefix(route, s.basePath)
+
+		if !state.allowsRoute(route) {
+			s.reject(state)
+			TibiaDataErrorHandler(c, validation.ErrorAPIKeyRouteNotAllowed, http.StatusForbidden)
+			c.Abort()
+			return
+		}
+
+		if retryAfter, err := s.take(state); err != nil {
+			if webServerOf(c).debugMode().Load() {
+				log.Printf("[info] TibiaData API key %s - (%s) rejected: %s", state.Name, c.Request.RequestURI, err)
+			}
+
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			TibiaDataErrorHandler(c, err, http.StatusTooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AdminMiddleware func - returns a gin middleware requiring an API key with admin access
+func (s *apiKeyStore) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state, ok := s.authenticate(c)
+		if !ok {
+			return
+		}
+
+		if !state.Admin {
+			s.reject(state)
+			TibiaDataErrorHandler(c, validation.ErrorAPIKeyRouteNotAllowed, http.StatusForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Usage func - returns the usage of every key sorted by name
+func (s *apiKeyStore) Usage() []APIKeyUsage {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := make([]APIKeyUsage, 0, len(s.keys))
+	for _, state := range s.keys {
+		entry := APIKeyUsage{
+			Name:              state.Name,
+			RequestsPerMinute: state.RequestsPerMinute,
+			RequestsPerDay:    state.RequestsPerDay,
+			Routes:            state.Routes,
+			Requests:          state.requests,
+			Rejected:          state.rejected,
+		}
+
+		// counters of past windows are reset on the next request, so they are hidden here
+		if state.minute.Equal(now.Truncate(time.Minute)) {
+			entry.MinuteRequests = state.minuteRequests
+		}
+		if state.day.Equal(now.UTC().Truncate(24 * time.Hour)) {
+			entry.DayRequests = state.dayRequests
+		}
+		if !state.lastUsed.IsZero() {
+			entry.LastUsed = state.lastUsed.UTC().Format(time.RFC3339)
+		}
+
+		usage = append(usage, entry)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Name < usage[j].Name
+	})
+
+	return usage
+}
+
+// apiKeysHandler returns the usage of the API keys
+func (s *webServer) apiKeysHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, APIKeysUsageResponse{
+		APIKeys: s.apiKeys.Usage(),
+		Information: Information{
+			APIDetails: TibiaDataAPIDetails,
+			Timestamp:  TibiaDataDatetime(""),
+			Status: Status{
+				HTTPCode: http.StatusOK,
+			},
+		},
+	})
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAPIKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	fileName := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(fileName, []byte(`[{"name":"team-a","key":"a","requests_per_minute":60,"routes":["/v4/highscores"]}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := loadAPIKeys(fileName)
+	assert.Nil(err)
+	assert.Equal([]APIKey{
+		{Name: "team-a", Key: "a", RequestsPerMinute: 60, Routes: []string{"/v4/highscores"}},
+	}, keys)
+
+	_, err = loadAPIKeys(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(err)
+
+	if err := os.WriteFile(fileName, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loadAPIKeys(fileName)
+	assert.NotNil(err)
+
+	// invalid keys are rejected
+	for _, keys := range [][]APIKey{
+		{{Key: "a"}},
+		{{Name: "team-a"}},
+		{{Name: "team-a", Key: "a", RequestsPerDay: -1}},
+		{{Name: "team-a", Key: "a"}, {Name: "team-b", Key: "a"}},
+	} {
+		_, err := newAPIKeyStore(keys)
+		assert.NotNil(err, "%v", keys)
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := newAPIKeyStore([]APIKey{
+		{Name: "highscores", Key: "highscores-key", Routes: []string{"/v4/highscores"}},
+		{Name: "minute", Key: "minute-key", RequestsPerMinute: 2},
+		{Name: "day", Key: "day-key", RequestsPerDay: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2023, 6, 1, 10, 0, 30, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	router := gin.New()
+	v4 := router.Group("/v4", store.Middleware())
+	v4.GET("/highscores/:world", func(c *gin.Context) { c.Status(http.StatusOK) })
+	v4.GET("/worlds", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path string, header, value string) (*httptest.ResponseRecorder, OutInformation) {
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+		router.ServeHTTP(w, request)
+
+		var output OutInformation
+		if w.Body.Len() > 0 {
+			if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		return w, output
+	}
+
+	// a key is required
+	w, output := serve("/v4/worlds", "", "")
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal(15001, output.Information.Status.Error)
+
+	w, output = serve("/v4/worlds", "X-API-Key", "unknown")
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal(15002, output.Information.Status.Error)
+
+	// the routes of a key are restricted to its route groups
+	w, _ = serve("/v4/highscores/Antica", "X-API-Key", "highscores-key")
+	assert.Equal(http.StatusOK, w.Code)
+
+	w, output = serve("/v4/worlds", "Authorization", "Bearer highscores-key")
+	assert.Equal(http.StatusForbidden, w.Code)
+	assert.Equal(15003, output.Information.Status.Error)
+
+	// the minute quota is reset every minute
+	for i := 0; i < 2; i++ {
+		w, _ = serve("/v4/worlds", "Authorization", "Bearer minute-key")
+		assert.Equal(http.StatusOK, w.Code)
+	}
+
+	w, output = serve("/v4/worlds", "X-API-Key", "minute-key")
+	assert.Equal(http.StatusTooManyRequests, w.Code)
+	assert.Equal(15004, output.Information.Status.Error)
+	assert.Equal("30", w.Header().Get("Retry-After"))
+
+	// the daily quota is reset every day
+	w, _ = serve("/v4/worlds", "X-API-Key", "day-key")
+	assert.Equal(http.StatusOK, w.Code)
+
+	w, output = serve("/v4/worlds", "X-API-Key", "day-key")
+	assert.Equal(http.StatusTooManyRequests, w.Code)
+	assert.Equal(15005, output.Information.Status.Error)
+
+	now = now.Add(time.Minute)
+
+	w, _ = serve("/v4/worlds", "X-API-Key", "minute-key")
+	assert.Equal(http.StatusOK, w.Code)
+
+	w, _ = serve("/v4/worlds", "X-API-Key", "day-key")
+	assert.Equal(http.StatusTooManyRequests, w.Code)
+
+	// requests of the cache warmer do not need a key
+	w = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+	router.ServeHTTP(w, request.WithContext(withCacheRefresh(request.Context())))
+	assert.Equal(http.StatusOK, w.Code)
+
+	usage := store.Usage()
+	assert.Equal([]APIKeyUsage{
+		{Name: "day", RequestsPerDay: 1, Requests: 1, Rejected: 2, DayRequests: 1, LastUsed: "2023-06-01T10:00:30Z"},
+		{Name: "highscores", Routes: []string{"/v4/highscores"}, Requests: 1, Rejected: 1, MinuteRequests: 0, DayRequests: 1, LastUsed: "2023-06-01T10:00:30Z"},
+		{Name: "minute", RequestsPerMinute: 2, Requests: 3, Rejected: 1, MinuteRequests: 1, DayRequests: 3, LastUsed: "2023-06-01T10:01:30Z"},
+	}, usage)
+}
+
+func TestAPIKeysAdmin(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := newAPIKeyStore([]APIKey{
+		{Name: "admin", Key: "admin-key", Admin: true},
+		{Name: "client", Key: "client-key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &webServer{apiKeys: store}
+
+	router := gin.New()
+	admin := router.Group("/admin", store.AdminMiddleware())
+	admin.GET("/apikeys", s.apiKeysHandler)
+
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/admin/apikeys", nil)
+	request.Header.Set("X-API-Key", "client-key")
+	router.ServeHTTP(w, request)
+	assert.Equal(http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/admin/apikeys", nil)
+	request.Header.Set("X-API-Key", "admin-key")
+	router.ServeHTTP(w, request)
+	assert.Equal(http.StatusOK, w.Code)
+
+	var output APIKeysUsageResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(output.APIKeys, 2)
+	assert.Equal(uint64(1), output.APIKeys[1].Rejected)
+	assert.NotContains(w.Body.String(), "client-key")
+}
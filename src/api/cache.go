@@ -0,0 +1,328 @@
+package api
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheInformation stores details about a cached response
+type CacheInformation struct {
+	Hit bool `json:"hit"` // Whether the response was served from the cache.
+	Age int  `json:"age"` // The number of seconds since the data was retrieved from tibia.com.
+}
+
+// CacheEntry is a serialized response stored in a ResponseCache
+type CacheEntry struct {
+	Data      []byte    `json:"data"`                 // The serialized response.
+	StoredAt  time.Time `json:"stored_at"`            // When the response was retrieved from tibia.com.
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // Until when the response may be cached (zero if it may not).
+}
+
+// CacheStats stores the counters of a ResponseCache
+type CacheStats struct {
+	Backend   string `json:"backend"`   // The backend used for caching.
+	Entries   int    `json:"entries"`   // The number of entries currently stored.
+	Bytes     int64  `json:"bytes"`     // The size of the entries currently stored.
+	Hits      uint64 `json:"hits"`      // The number of lookups served from the cache.
+	Misses    uint64 `json:"misses"`    // The number of lookups not found in the cache.
+	Evictions uint64 `json:"evictions"` // The number of entries removed to stay within the limits.
+	Errors    uint64 `json:"errors"`    // The number of failed operations on the backend.
+}
+
+// ResponseCache stores serialized responses for a limited time
+type ResponseCache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry, ttl time.Duration)
+	Delete(key string) (int, error)          // removes the entry of key and returns how many entries were removed
+	DeletePrefix(prefix string) (int, error) // removes the entries whose key starts with prefix and returns how many were removed
+	Stats() CacheStats
+}
+
+// cachePolicy returns how long the response data may be cached with the server save schedule of the router
+type cachePolicy func(data interface{}, serverSave *serverSaveSchedule) time.Duration
+
+// cacheFor func - returns a cachePolicy with a fixed time-to-live
+func cacheFor(ttl time.Duration) cachePolicy {
+	return func(interface{}, *serverSaveSchedule) time.Duration {
+		return ttl
+	}
+}
+
+// TibiaDataCachePolicies holds the cachePolicy of every handler name
+// handlers not listed here will not be cached
+var TibiaDataCachePolicies = map[string]cachePolicy{
+	"TibiaBoostableBosses":     cacheUntilServerSave,
+	"TibiaCharactersCharacter": cacheFor(1 * time.Minute),
+	"TibiaCreaturesCreature":   cacheFor(6 * time.Hour),
+	"TibiaCreaturesOverview":   cacheUntilServerSave,
+	"TibiaFansites":            cacheFor(1 * time.Hour),
+	"TibiaGuildsGuild":         cacheFor(5 * time.Minute),
+	"TibiaGuildsOverview":      cacheFor(15 * time.Minute),
+	"TibiaHighscores":          highscoresCachePolicy,
+	"TibiaHousesHouse":         cacheFor(5 * time.Minute),
+	"TibiaHousesOverview":      cacheFor(5 * time.Minute),
+	"TibiaKillstatistics":      cacheFor(15 * time.Minute),
+	"TibiaNews":                cacheFor(1 * time.Hour),
+	"TibiaNewslist":            cacheFor(15 * time.Minute),
+	"TibiaSpellsOverview":      cacheFor(6 * time.Hour),
+	"TibiaSpellsSpell":         cacheFor(6 * time.Hour),
+	"TibiaWorldsOverview":      cacheFor(1 * time.Minute),
+	"TibiaWorldsWorld":         cacheFor(1 * time.Minute),
+}
+
+// highscoresCachePolicy caches highscores until tibia.com updates them (every hour)
+func highscoresCachePolicy(data interface{}, _ *serverSaveSchedule) time.Duration {
+	if response, ok := data.(*HighscoresResponse); ok && response.Highscores.HighscoreAge < 60 {
+		return time.Duration(60-response.Highscores.HighscoreAge) * time.Minute
+	}
+
+	return 1 * time.Minute
+}
+
+// cacheUntilServerSave caches responses until the next server save, when the boosted creature and boss change
+func cacheUntilServerSave(_ interface{}, serverSave *serverSaveSchedule) time.Duration {
+	// tibia.com may still answer with the data of the day before during the server save
+	if serverSave.InProgress() {
+		return 1 * time.Minute
+	}
+
+	return serverSave.Next().Sub(serverSave.now())
+}
+
+// withInformation func - returns the entry data with the information block changed by update
+func (e CacheEntry) withInformation(update func(information *Information)) []byte {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(e.Data, &response); err != nil {
+		return e.Data
+	}
+
+	rawInformation, ok := response["information"]
+	if !ok {
+		return e.Data
+	}
+
+	var information Information
+	if err := json.Unmarshal(rawInformation, &information); err != nil {
+		return e.Data
+	}
+
+	update(&information)
+
+	newInformation, err := json.Marshal(information)
+	if err != nil {
+		return e.Data
+	}
+
+	// replacing the raw information block keeps the order of the other fields
+	return bytes.Replace(e.Data, rawInformation, newInformation, 1)
+}
+
+// withCacheInformation func - returns the entry data with the cache and server save details in the information block
+func (e CacheEntry) withCacheInformation(hit bool, now time.Time, serverSave *serverSaveSchedule) []byte {
+	return e.withInformation(func(information *Information) {
+		serverSave.apply(information)
+		information.Cache = &CacheInformation{
+			Hit: hit,
+			Age: int(now.Sub(e.StoredAt).Seconds()),
+		}
+	})
+}
+
+// TibiaDataAPIHandleCachedResponse func - handling of responses passing through the cache
+// This should NOT be invoked if an error occured
+func TibiaDataAPIHandleCachedResponse(c *gin.Context, s string, entry CacheEntry, hit bool) {
+	now := time.Now()
+	data := entry.withCacheInformation(hit, now, webServerOf(c).serverSaveSchedule())
+
+	if webServerOf(c).debugMode().Load() {
+		log.Printf("[info] %s - (%s) executed successfully (cache hit: %t).", s, c.Request.RequestURI, hit)
+	}
+
+	TibiaDataSetCacheHeaders(c, entry, now)
+	tibiaDataWriteSerializedResponse(c, s, data)
+}
+
+// TibiaDataSetCacheHeaders func - sets Cache-Control, Expires and Last-Modified of a response
+// so caches in front of the API keep the response as long as the API itself does
+func TibiaDataSetCacheHeaders(c *gin.Context, entry CacheEntry, now time.Time) {
+	if !entry.StoredAt.IsZero() {
+		c.Header("Last-Modified", entry.StoredAt.UTC().Format(http.TimeFormat))
+	}
+
+	maxAge := int(entry.ExpiresAt.Sub(now).Round(time.Second).Seconds())
+	if entry.ExpiresAt.IsZero() || maxAge <= 0 {
+		c.Header("Cache-Control", "no-cache")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	c.Header("Expires", entry.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// tibiaDataWriteSerializedResponse func - writes an already serialized response
+// clients sending the ETag of the response in If-None-Match get a 304 Not Modified
+func tibiaDataWriteSerializedResponse(c *gin.Context, s string, data []byte) {
+	etag := tibiaDataETag(data)
+	c.Header("ETag", etag)
+
+	if c.Request != nil && tibiaDataETagMatches(c.GetHeader("If-None-Match"), etag) {
+		if webServerOf(c).debugMode().Load() {
+			log.Printf("[info] %s - (%s) not modified.", s, c.Request.RequestURI)
+		}
+
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	// print to log about request
+	if gin.IsDebugging() {
+		log.Println("[debug] " + s + " - (" + c.Request.RequestURI + ") returned data:")
+		log.Printf("[debug] %s\n", data)
+	}
+
+	// return successful response
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// memoryCacheItem is an element of the memoryCache list
+type memoryCacheItem struct {
+	key       string
+	entry     CacheEntry
+	expiresAt time.Time
+}
+
+// memoryCache is an in-process ResponseCache evicting the least recently used entries
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int   // maximum number of entries (0 for no limit)
+	maxBytes   int64 // maximum size of all entries (0 for no limit)
+	bytes      int64
+	ll         *list.List
+	items      map[string]*list.Element
+
+	hits, misses, evictions uint64
+}
+
+// newMemoryCache func - creates a memoryCache with the given limits
+func newMemoryCache(maxEntries int, maxBytes int64) *memoryCache {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get func - returns the entry of key if it exists and has not expired
+func (m *memoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		m.misses++
+		return CacheEntry{}, false
+	}
+
+	item := element.Value.(*memoryCacheItem)
+	if time.Now().After(item.expiresAt) {
+		m.removeElement(element)
+		m.misses++
+		return CacheEntry{}, false
+	}
+
+	m.ll.MoveToFront(element)
+	m.hits++
+
+	return item.entry, true
+}
+
+// Set func - stores the entry of key for the duration of ttl
+func (m *memoryCache) Set(key string, entry CacheEntry, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// entries bigger than the cache itself are never stored
+	if m.maxBytes > 0 && int64(len(entry.Data)) > m.maxBytes {
+		return
+	}
+
+	if element, ok := m.items[key]; ok {
+		m.removeElement(element)
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryCacheItem{
+		key:       key,
+		entry:     entry,
+		expiresAt: time.Now().Add(ttl),
+	})
+	m.bytes += int64(len(entry.Data))
+
+	// evicting the least recently used entries until the limits are respected
+	for (m.maxEntries > 0 && m.ll.Len() > m.maxEntries) || (m.maxBytes > 0 && m.bytes > m.maxBytes) {
+		m.removeElement(m.ll.Back())
+		m.evictions++
+	}
+}
+
+// Delete func - removes the entry of key
+func (m *memoryCache) Delete(key string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return 0, nil
+	}
+	m.removeElement(element)
+
+	return 1, nil
+}
+
+// DeletePrefix func - removes the entries whose key starts with prefix
+func (m *memoryCache) DeletePrefix(prefix string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for key, element := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			m.removeElement(element)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Stats func - returns the counters of the cache
+func (m *memoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return CacheStats{
+		Backend:   "memory",
+		Entries:   m.ll.Len(),
+		Bytes:     m.bytes,
+		Hits:      m.hits,
+		Misses:    m.misses,
+		Evictions: m.evictions,
+	}
+}
+
+// removeElement func - removes an element, the lock must be held
+func (m *memoryCache) removeElement(element *list.Element) {
+	item := m.ll.Remove(element).(*memoryCacheItem)
+	delete(m.items, item.key)
+	m.bytes -= int64(len(item.entry.Data))
+}
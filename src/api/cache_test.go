@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newMemoryCache(2, 0)
+
+	_, ok := cache.Get("a")
+	assert.False(ok)
+
+	cache.Set("a", CacheEntry{Data: []byte("a")}, time.Minute)
+	cache.Set("b", CacheEntry{Data: []byte("b")}, time.Minute)
+
+	entry, ok := cache.Get("a")
+	assert.True(ok)
+	assert.Equal([]byte("a"), entry.Data)
+
+	// b is the least recently used entry and gets evicted
+	cache.Set("c", CacheEntry{Data: []byte("c")}, time.Minute)
+
+	_, ok = cache.Get("b")
+	assert.False(ok)
+
+	_, ok = cache.Get("c")
+	assert.True(ok)
+
+	// expired entries are not returned
+	cache.Set("d", CacheEntry{Data: []byte("d")}, -time.Second)
+
+	_, ok = cache.Get("d")
+	assert.False(ok)
+
+	assert.Equal(CacheStats{
+		Backend:   "memory",
+		Entries:   1,
+		Bytes:     1,
+		Hits:      2,
+		Misses:    3,
+		Evictions: 2,
+	}, cache.Stats())
+}
+
+func TestMemoryCacheDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newMemoryCache(0, 0)
+	for _, key := range []string{"GET https://www.tibia.com/community/?subtopic=worlds", "GET https://www.tibia.com/community/?subtopic=worlds&world=Antica", "GET https://www.tibia.com/library/?subtopic=creatures"} {
+		cache.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+	}
+
+	deleted, err := cache.Delete("GET https://www.tibia.com/community/?subtopic=worlds")
+	assert.Nil(err)
+	assert.Equal(1, deleted)
+
+	deleted, _ = cache.Delete("GET https://www.tibia.com/community/?subtopic=worlds")
+	assert.Equal(0, deleted)
+
+	deleted, err = cache.DeletePrefix("GET https://www.tibia.com/community/")
+	assert.Nil(err)
+	assert.Equal(1, deleted)
+
+	assert.Equal(1, cache.Stats().Entries)
+	assert.EqualValues(4, cache.Stats().Bytes)
+
+	// an empty prefix removes all entries
+	deleted, _ = cache.DeletePrefix("")
+	assert.Equal(1, deleted)
+	assert.Equal(0, cache.Stats().Entries)
+}
+
+func TestMemoryCacheMaxBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newMemoryCache(0, 10)
+
+	cache.Set("a", CacheEntry{Data: []byte("aaaaa")}, time.Minute)
+	cache.Set("b", CacheEntry{Data: []byte("bbbbb")}, time.Minute)
+	cache.Set("c", CacheEntry{Data: []byte("ccccc")}, time.Minute)
+
+	_, ok := cache.Get("a")
+	assert.False(ok)
+
+	// entries bigger than the cache are not stored
+	cache.Set("d", CacheEntry{Data: []byte("ddddddddddd")}, time.Minute)
+
+	_, ok = cache.Get("d")
+	assert.False(ok)
+
+	stats := cache.Stats()
+	assert.Equal(2, stats.Entries)
+	assert.EqualValues(10, stats.Bytes)
+}
+
+func TestHighscoresCachePolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(45*time.Minute, highscoresCachePolicy(&HighscoresResponse{Highscores: Highscores{HighscoreAge: 15}}, TibiaDataServerSave))
+	assert.Equal(time.Minute, highscoresCachePolicy(&HighscoresResponse{Highscores: Highscores{HighscoreAge: 60}}, TibiaDataServerSave))
+	assert.Equal(time.Minute, highscoresCachePolicy(nil, TibiaDataServerSave))
+}
+
+func TestCacheUntilServerSave(t *testing.T) {
+	assert := assert.New(t)
+
+	serverSave := newServerSaveSchedule(10 * time.Minute)
+
+	// 10:00 UTC is 12:00 CEST, two hours after the server save
+	serverSave.now = func() time.Time { return time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC) }
+	assert.Equal(22*time.Hour, cacheUntilServerSave(nil, serverSave))
+
+	// during the server save
+	serverSave.now = func() time.Time { return time.Date(2023, 6, 1, 8, 5, 0, 0, time.UTC) }
+	assert.Equal(time.Minute, cacheUntilServerSave(nil, serverSave))
+
+	// after a shorter server save
+	serverSave.duration = 2 * time.Minute
+	assert.Equal(23*time.Hour+55*time.Minute, cacheUntilServerSave(nil, serverSave))
+}
+
+func TestCacheHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 1, 30, 0, time.UTC)
+	entry := CacheEntry{
+		StoredAt:  now.Add(-90 * time.Second),
+		ExpiresAt: now.Add(30 * time.Second),
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	TibiaDataSetCacheHeaders(c, entry, now)
+
+	assert.Equal("public, max-age=30", w.Header().Get("Cache-Control"))
+	assert.Equal("Thu, 01 Jun 2023 10:02:00 GMT", w.Header().Get("Expires"))
+	assert.Equal("Thu, 01 Jun 2023 10:00:00 GMT", w.Header().Get("Last-Modified"))
+
+	// expired entries and entries without expiry must be revalidated
+	for _, expiresAt := range []time.Time{now.Add(-time.Second), {}} {
+		entry.ExpiresAt = expiresAt
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		TibiaDataSetCacheHeaders(c, entry, now)
+
+		assert.Equal("no-cache", w.Header().Get("Cache-Control"))
+		assert.Empty(w.Header().Get("Expires"))
+		assert.Equal("Thu, 01 Jun 2023 10:00:00 GMT", w.Header().Get("Last-Modified"))
+	}
+}
+
+func TestCacheEntryWithCacheInformation(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 1, 30, 0, time.UTC)
+	entry := CacheEntry{
+		Data:     []byte(`{"worlds":{"regular_worlds":[]},"information":{"api":{"version":4,"release":"unknown","commit":"-"},"timestamp":"2023-06-01T10:00:00Z","status":{"http_code":200}}}`),
+		StoredAt: now.Add(-90 * time.Second),
+	}
+
+	serverSave := newServerSaveSchedule(10 * time.Minute)
+	serverSave.now = func() time.Time { return now }
+
+	assert.Equal(`{"worlds":{"regular_worlds":[]},"information":{"api":{"version":4,"release":"unknown","commit":"-"},"timestamp":"2023-06-01T10:00:00Z","status":{"http_code":200},"cache":{"hit":true,"age":90},"next_server_save":"2023-06-02T08:00:00Z"}}`, string(entry.withCacheInformation(true, now, serverSave)))
+
+	// data without information is returned as is
+	entry.Data = []byte(`{"status":"OK"}`)
+	assert.Equal(`{"status":"OK"}`, string(entry.withCacheInformation(true, now, serverSave)))
+}
+
+func TestCachedRequestHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	fetches := 0
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			fetches++
+			return "content", nil
+		}),
+		cache: newMemoryCache(10, 0),
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return OutInformation{Information: Information{Status: Status{HTTPCode: http.StatusOK}}}, nil
+	}
+
+	for i, hit := range []bool{false, true} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+		s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+		assert.Equal(http.StatusOK, w.Code)
+
+		var output OutInformation
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(hit, output.Information.Cache.Hit, "request %d", i)
+		assert.Regexp(`^public, max-age=(59|60)$`, w.Header().Get("Cache-Control"), "request %d", i)
+		assert.NotEmpty(w.Header().Get("Expires"), "request %d", i)
+		assert.NotEmpty(w.Header().Get("Last-Modified"), "request %d", i)
+	}
+
+	assert.Equal(1, fetches)
+
+	// handlers without cache policy are not cached
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+	s.tibiaDataRequestHandler(c, request, requestHandler, "TestCachedRequestHandler")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(2, fetches)
+	assert.NotContains(w.Body.String(), `"cache"`)
+	assert.Equal("no-cache", w.Header().Get("Cache-Control"))
+}
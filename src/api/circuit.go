@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+)
+
+// the states of tibia.com tracked by the upstreamCircuit
+const (
+	upstreamHealthy     = "healthy"     // requests succeed
+	upstreamDegraded    = "degraded"    // requests fail (the circuit opens after too many failures)
+	upstreamMaintenance = "maintenance" // tibia.com redirects to maintenance.tibia.com
+	upstreamThrottled   = "throttled"   // tibia.com answers with 403
+)
+
+// UpstreamStatus stores the state of tibia.com as seen by the API
+type UpstreamStatus struct {
+	State      string `json:"state"`       // One of healthy, degraded, maintenance or throttled.
+	Since      string `json:"since"`       // When the state was entered.
+	Open       bool   `json:"open"`        // Whether requests to tibia.com are short-circuited.
+	RetryAfter int    `json:"retry_after"` // The number of seconds until tibia.com is tried again.
+	Failures   int    `json:"failures"`    // The number of consecutive failed requests.
+}
+
+// upstreamCircuit is a circuit breaker for requests to tibia.com
+// while open, requests fail right away instead of hammering the site
+// once openUntil is reached a single request probes whether tibia.com recovered
+type upstreamCircuit struct {
+	mu               sync.Mutex
+	failureThreshold int           // consecutive failures opening the circuit
+	openDuration     time.Duration // how long the circuit stays open after failures
+	maintenanceDelay time.Duration // how long the circuit stays open during maintenance
+
+	state     string
+	since     time.Time
+	failures  int
+	openUntil time.Time
+	openErr   error // the error returned while the circuit is open
+	probing   bool  // whether a request is probing tibia.com
+
+	now func() time.Time
+}
+
+// newUpstreamCircuit func - creates an upstreamCircuit in the healthy state
+func newUpstreamCircuit(failureThreshold int, openDuration, maintenanceDelay time.Duration) *upstreamCircuit {
+	return &upstreamCircuit{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		maintenanceDelay: maintenanceDelay,
+		state:            upstreamHealthy,
+		since:            time.Now(),
+		now:              time.Now,
+	}
+}
+
+// Allow func - returns an error if the request must not be sent to tibia.com
+// probe is true for the single request probing tibia.com while the circuit is half-open,
+// its outcome must be passed to Record or Abandon
+func (u *upstreamCircuit) Allow() (probe bool, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.openUntil.IsZero() {
+		return false, nil
+	}
+
+	now := u.now()
+	if now.Before(u.openUntil) {
+		return false, tibiaDataRetryAfterError{u.openErr, u.retryAfter(now)}
+	}
+
+	// other requests wait for the outcome of the probe
+	if u.probing {
+		return false, tibiaDataRetryAfterError{u.openErr, time.Second}
+	}
+
+	// half-open: the next request probes tibia.com
+	u.probing = true
+
+	return true, nil
+}
+
+// Abandon func - releases the probe of a request that was not sent to tibia.com
+func (u *upstreamCircuit) Abandon(probe bool) {
+	if !probe {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.probing = false
+}
+
+// Record func - updates the state with the outcome of a request to tibia.com
+// probe is the value returned by Allow for the request
+// returns err with a Retry-After if the circuit was opened
+func (u *upstreamCircuit) Record(probe bool, err error) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := u.now()
+
+	// requests sent before the circuit opened do not end the probe
+	if probe {
+		u.probing = false
+	}
+
+	// requests cancelled by the client say nothing about tibia.com
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	// a page that does not exist is an answer of a healthy tibia.com
+	if err == nil || errors.Is(err, validation.ErrorUpstreamPageNotFound) {
+		if u.state != upstreamHealthy {
+			log.Printf("[info] TibiaData API upstream circuit: tibia.com is healthy again (was %s)", u.state)
+			u.setState(upstreamHealthy, now)
+		}
+		u.failures = 0
+		u.openUntil = time.Time{}
+		u.openErr = nil
+		return err
+	}
+
+	u.failures++
+
+	var retryAfterErr tibiaDataRetryAfterError
+	switch {
+	case errors.Is(err, validation.ErrorMaintenanceMode):
+		u.open(upstreamMaintenance, validation.ErrorMaintenanceMode, u.maintenanceDelay, now)
+	case errors.Is(err, validation.ErrorUpstreamThrottled):
+		delay := u.openDuration
+		if errors.As(err, &retryAfterErr) && retryAfterErr.retryAfter > 0 {
+			delay = retryAfterErr.retryAfter
+		}
+		u.open(upstreamThrottled, validation.ErrorUpstreamThrottled, delay, now)
+	case u.failureThreshold > 0 && u.failures >= u.failureThreshold:
+		u.open(upstreamDegraded, validation.ErrorUpstreamUnavailable, u.openDuration, now)
+	default:
+		if u.state == upstreamHealthy {
+			u.setState(upstreamDegraded, now)
+		}
+		return err
+	}
+
+	// maintenance and throttling are reported with their validation error
+	if errors.Is(err, u.openErr) {
+		err = u.openErr
+	}
+
+	return tibiaDataRetryAfterError{err, u.retryAfter(now)}
+}
+
+// Status func - returns the current state of tibia.com
+func (u *upstreamCircuit) Status() UpstreamStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := u.now()
+
+	return UpstreamStatus{
+		State:      u.state,
+		Since:      u.since.UTC().Format(time.RFC3339),
+		Open:       now.Before(u.openUntil),
+		RetryAfter: int(u.retryAfter(now).Seconds()),
+		Failures:   u.failures,
+	}
+}
+
+// open func - opens the circuit for the duration of delay, the lock must be held
+func (u *upstreamCircuit) open(state string, openErr error, delay time.Duration, now time.Time) {
+	if u.state != state {
+		log.Printf("[warning] TibiaData API upstream circuit: tibia.com is %s, pausing requests for %s", state, delay)
+		u.setState(state, now)
+	}
+
+	u.openUntil = now.Add(delay)
+	u.openErr = openErr
+}
+
+// setState func - changes the state, the lock must be held
+func (u *upstreamCircuit) setState(state string, now time.Time) {
+	u.state = state
+	u.since = now
+}
+
+// retryAfter func - returns the time until the circuit closes, the lock must be held
+func (u *upstreamCircuit) retryAfter(now time.Time) time.Duration {
+	if now.After(u.openUntil) {
+		return 0
+	}
+
+	return u.openUntil.Sub(now)
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamCircuit(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	circuit := newUpstreamCircuit(3, 30*time.Second, time.Minute)
+	circuit.now = func() time.Time { return now }
+
+	probe, err := circuit.Allow()
+	assert.Nil(err)
+	assert.False(probe)
+	assert.Nil(circuit.Record(probe, nil))
+	assert.Equal(upstreamHealthy, circuit.Status().State)
+
+	// failures below the threshold degrade the state but keep the circuit closed
+	requestErr := errors.New("connection reset")
+	for i := 0; i < 2; i++ {
+		assert.Equal(requestErr, circuit.Record(false, requestErr))
+		_, err = circuit.Allow()
+		assert.Nil(err)
+	}
+	assert.Equal(upstreamDegraded, circuit.Status().State)
+	assert.False(circuit.Status().Open)
+
+	// the failure reaching the threshold opens the circuit
+	var retryAfterErr tibiaDataRetryAfterError
+	assert.True(errors.As(circuit.Record(false, requestErr), &retryAfterErr))
+	assert.Equal(requestErr, retryAfterErr.err)
+	assert.Equal(30*time.Second, retryAfterErr.retryAfter)
+
+	now = now.Add(10 * time.Second)
+	_, err = circuit.Allow()
+	assert.True(errors.As(err, &retryAfterErr))
+	assert.Equal(validation.ErrorUpstreamUnavailable, retryAfterErr.err)
+	assert.Equal(20*time.Second, retryAfterErr.retryAfter)
+
+	status := circuit.Status()
+	assert.True(status.Open)
+	assert.Equal(20, status.RetryAfter)
+	assert.Equal(3, status.Failures)
+
+	// once open, a single request probes tibia.com
+	now = now.Add(30 * time.Second)
+	probe, err = circuit.Allow()
+	assert.Nil(err)
+	assert.True(probe)
+	_, err = circuit.Allow()
+	assert.NotNil(err)
+
+	// requests sent before the circuit opened do not end the probe
+	assert.Equal(context.Canceled, circuit.Record(false, context.Canceled))
+	_, err = circuit.Allow()
+	assert.NotNil(err)
+
+	// a successful probe closes the circuit
+	assert.Nil(circuit.Record(probe, nil))
+	probe, err = circuit.Allow()
+	assert.Nil(err)
+	assert.False(probe)
+	assert.Equal(upstreamHealthy, circuit.Status().State)
+	assert.Equal(0, circuit.Status().Failures)
+}
+
+func TestUpstreamCircuitAbandon(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	circuit := newUpstreamCircuit(1, 30*time.Second, time.Minute)
+	circuit.now = func() time.Time { return now }
+
+	circuit.Record(false, errors.New("connection reset"))
+	assert.True(circuit.Status().Open)
+
+	// a probe that was not sent to tibia.com lets the next request probe
+	now = now.Add(time.Minute)
+	probe, err := circuit.Allow()
+	assert.Nil(err)
+	assert.True(probe)
+
+	circuit.Abandon(probe)
+	probe, err = circuit.Allow()
+	assert.Nil(err)
+	assert.True(probe)
+	assert.Equal(upstreamDegraded, circuit.Status().State)
+}
+
+func TestUpstreamCircuitMaintenanceAndThrottling(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	circuit := newUpstreamCircuit(3, 30*time.Second, time.Minute)
+	circuit.now = func() time.Time { return now }
+
+	// maintenance opens the circuit right away
+	var retryAfterErr tibiaDataRetryAfterError
+	assert.True(errors.As(circuit.Record(false, validation.ErrorMaintenanceMode), &retryAfterErr))
+	assert.Equal(validation.ErrorMaintenanceMode, retryAfterErr.err)
+	assert.Equal(time.Minute, retryAfterErr.retryAfter)
+	assert.Equal(upstreamMaintenance, circuit.Status().State)
+
+	_, err := circuit.Allow()
+	assert.True(errors.Is(err, validation.ErrorMaintenanceMode))
+
+	// throttling keeps the circuit open as long as the limiter backs off
+	now = now.Add(time.Minute)
+	probe, err := circuit.Allow()
+	assert.Nil(err)
+	assert.True(errors.As(circuit.Record(probe, tibiaDataRetryAfterError{validation.ErrorUpstreamThrottled, 10 * time.Second}), &retryAfterErr))
+	assert.Equal(validation.ErrorUpstreamThrottled, retryAfterErr.err)
+	assert.Equal(10*time.Second, retryAfterErr.retryAfter)
+	assert.Equal(upstreamThrottled, circuit.Status().State)
+	assert.Equal(now.Format(time.RFC3339), circuit.Status().Since)
+}
+
+func TestUpstreamCircuitShortCircuit(t *testing.T) {
+	assert := assert.New(t)
+
+	fetches := 0
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			fetches++
+			return "", validation.ErrorMaintenanceMode
+		}),
+		circuit: newUpstreamCircuit(3, 30*time.Second, time.Minute),
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return nil, errors.New("not reached")
+	}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+		s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+
+		var output OutInformation
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(http.StatusServiceUnavailable, w.Code)
+		assert.NotEmpty(w.Header().Get("Retry-After"))
+		assert.Equal(validation.ErrorMaintenanceMode.Code(), output.Information.Status.Error)
+	}
+
+	// the second request did not reach tibia.com
+	assert.Equal(1, fetches)
+
+	// the state is visible on the debug endpoint
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/debug", nil)
+	s.debugHandler(c)
+	assert.Contains(w.Body.String(), `"state":"maintenance"`)
+}
+
+func TestUpstreamCircuitQueueErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	// the local limiter gives up before the request is sent to tibia.com
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			return "", tibiaDataQueueError{tibiaDataRetryAfterError{validation.ErrorUpstreamThrottled, 2 * time.Second}}
+		}),
+		circuit: newUpstreamCircuit(3, 30*time.Second, time.Minute),
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return nil, errors.New("not reached")
+	}
+
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+		s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+
+		assert.Equal(http.StatusServiceUnavailable, w.Code)
+		assert.Equal("2", w.Header().Get("Retry-After"))
+	}
+
+	// a saturated queue does not open the circuit
+	status := s.circuit.Status()
+	assert.Equal(upstreamHealthy, status.State)
+	assert.False(status.Open)
+	assert.Equal(0, status.Failures)
+}
+
+func TestUpstreamCircuitLocalErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	var fetchErr error
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			if fetchErr == nil {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return "", fetchErr
+		}),
+		circuit:         newUpstreamCircuit(3, 30*time.Second, time.Minute),
+		defaultDeadline: 20 * time.Millisecond,
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=characters&name=Nobody"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return nil, errors.New("not reached")
+	}
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v4/character/Nobody", nil)
+		s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaCharactersCharacter")
+		return w
+	}
+
+	// requests running out of the deadline budget of the handler are not failures of tibia.com
+	for i := 0; i < 5; i++ {
+		assert.Equal(http.StatusGatewayTimeout, serve().Code)
+	}
+	assert.Equal(0, s.circuit.Status().Failures)
+
+	// pages that do not exist are answers of tibia.com
+	fetchErr = validation.ErrorUpstreamPageNotFound
+	for i := 0; i < 5; i++ {
+		w := serve()
+		assert.Equal(http.StatusNotFound, w.Code)
+		assert.Contains(w.Body.String(), `"error":20009`)
+	}
+
+	status := s.circuit.Status()
+	assert.Equal(upstreamHealthy, status.State)
+	assert.False(status.Open)
+	assert.Equal(0, status.Failures)
+}
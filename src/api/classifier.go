@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+)
+
+// upstreamPageType is the kind of page tibia.com answered with
+type upstreamPageType int
+
+const (
+	upstreamPageContent       upstreamPageType = iota // a page with a box content to parse
+	upstreamPageMaintenance                           // tibia.com is in maintenance
+	upstreamPageThrottled                             // a captcha or rate limit page
+	upstreamPageError                                 // a generic error page
+	upstreamPageNotFound                              // the page does not exist
+	upstreamPageUnknownLayout                         // no box content was found
+)
+
+func (t upstreamPageType) String() string {
+	switch t {
+	case upstreamPageContent:
+		return "content"
+	case upstreamPageMaintenance:
+		return "maintenance"
+	case upstreamPageThrottled:
+		return "throttled"
+	case upstreamPageError:
+		return "error"
+	case upstreamPageNotFound:
+		return "not found"
+	default:
+		return "unknown layout"
+	}
+}
+
+// Err func - returns the validation error of the page type (nil for content)
+func (t upstreamPageType) Err() error {
+	switch t {
+	case upstreamPageContent:
+		return nil
+	case upstreamPageMaintenance:
+		return validation.ErrorMaintenanceMode
+	case upstreamPageThrottled:
+		return validation.ErrorUpstreamThrottled
+	case upstreamPageError:
+		return validation.ErrorUpstreamErrorPage
+	case upstreamPageNotFound:
+		return validation.ErrorUpstreamPageNotFound
+	default:
+		return validation.ErrorUpstreamUnknownLayout
+	}
+}
+
+// upstreamThrottleSelector matches the captcha and challenge forms shown instead of the content when too many requests are sent
+const upstreamThrottleSelector = `[id*="captcha" i], [class*="captcha" i], [name*="captcha" i], #challenge-form, .cf-challenge`
+
+// classifyUpstreamPage func - returns the type of the page tibia.com answered with
+func classifyUpstreamPage(statusCode int, doc *goquery.Document) upstreamPageType {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return upstreamPageNotFound
+	case statusCode == http.StatusForbidden, statusCode == http.StatusTooManyRequests:
+		return upstreamPageThrottled
+	case statusCode >= http.StatusInternalServerError:
+		return upstreamPageError
+	}
+
+	if doc.Find(upstreamThrottleSelector).Length() > 0 {
+		return upstreamPageThrottled
+	}
+
+	// the title of tibia.com pages is "Tibia - Free Multiplayer Online Role Playing Game - <section>"
+	title := strings.ToLower(doc.Find("title").First().Text())
+	switch {
+	case strings.Contains(title, "maintenance"):
+		return upstreamPageMaintenance
+	case strings.Contains(title, "not found"):
+		return upstreamPageNotFound
+	case strings.Contains(title, "error"):
+		return upstreamPageError
+	}
+
+	if strings.TrimSpace(doc.Find(".Border_2 .Border_3").Text()) == "" {
+		return upstreamPageUnknownLayout
+	}
+
+	return upstreamPageContent
+}
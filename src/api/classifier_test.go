@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyUpstreamPage(t *testing.T) {
+	assert := assert.New(t)
+
+	classify := func(statusCode int, html string) upstreamPageType {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return classifyUpstreamPage(statusCode, doc)
+	}
+
+	content := `<html><head><title>Tibia - Free Multiplayer Online Role Playing Game - Community</title></head><body><div class="Border_2"><div class="Border_3"><p>content</p></div></div></body></html>`
+	assert.Equal(upstreamPageContent, classify(http.StatusOK, content))
+	assert.Nil(upstreamPageContent.Err())
+
+	assert.Equal(upstreamPageNotFound, classify(http.StatusNotFound, content))
+	assert.Equal(upstreamPageError, classify(http.StatusInternalServerError, content))
+	assert.Equal(upstreamPageThrottled, classify(http.StatusTooManyRequests, content))
+
+	assert.Equal(upstreamPageThrottled, classify(http.StatusOK, `<html><body><form id="CaptchaForm"><input name="g-recaptcha-response"></form></body></html>`))
+	assert.Equal(upstreamPageMaintenance, classify(http.StatusOK, `<html><head><title>Tibia - Maintenance</title></head><body></body></html>`))
+	assert.Equal(upstreamPageError, classify(http.StatusOK, `<html><head><title>Tibia - Free Multiplayer Online Role Playing Game - Error</title></head><body></body></html>`))
+	assert.Equal(upstreamPageNotFound, classify(http.StatusOK, `<html><head><title>404 Not Found</title></head><body></body></html>`))
+
+	// a layout change without the box content
+	assert.Equal(upstreamPageUnknownLayout, classify(http.StatusOK, `<html><head><title>Tibia</title></head><body><div class="Content"><p>content</p></div></body></html>`))
+	assert.Equal(upstreamPageUnknownLayout, classify(http.StatusOK, `<html><body><div class="Border_2"><div class="Border_3"> </div></div></body></html>`))
+
+	// every page type has its own error code
+	codes := map[int]bool{}
+	for _, pageType := range []upstreamPageType{upstreamPageMaintenance, upstreamPageThrottled, upstreamPageError, upstreamPageNotFound, upstreamPageUnknownLayout} {
+		err, ok := pageType.Err().(validation.Error)
+		assert.True(ok, pageType.String())
+		codes[err.Code()] = true
+	}
+	assert.Len(codes, 5)
+}
+
+func TestEmptyParseIsUnknownLayout(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := TibiaWorldsOverviewImpl(`<div class="TableContentContainer"></div>`)
+	assert.Equal(validation.ErrorUpstreamUnknownLayout, err)
+
+	_, err = TibiaCreaturesOverviewImpl(`<div class="Boosted"></div>`)
+	assert.Equal(validation.ErrorUpstreamUnknownLayout, err)
+}
+
+func TestUpstreamErrorStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(http.StatusNotFound, upstreamErrorStatus(validation.ErrorUpstreamPageNotFound))
+	assert.Equal(http.StatusServiceUnavailable, upstreamErrorStatus(validation.ErrorUpstreamThrottled))
+	assert.Equal(http.StatusInternalServerError, upstreamErrorStatus(validation.ErrorUpstreamUnknownLayout))
+	assert.Equal(http.StatusGatewayTimeout, upstreamErrorStatus(context.DeadlineExceeded))
+	assert.Equal(http.StatusBadGateway, upstreamErrorStatus(validation.ErrorUpstreamErrorPage))
+	assert.Equal(http.StatusBadGateway, upstreamErrorStatus(validation.ErrorMaintenanceMode))
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// clientLimiterSweepInterval is how often the buckets of clients that are full again are removed
+const clientLimiterSweepInterval = 1 * time.Minute
+
+// clientBucket is the token bucket of a client
+type clientBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// clientLimit is the result of taking a token of a client
+type clientLimit struct {
+	allowed    bool
+	limit      int           // the size of the bucket
+	remaining  int           // the tokens left
+	reset      time.Duration // until the bucket is full again
+	retryAfter time.Duration // until the next token (only when not allowed)
+}
+
+// clientLimiter keeps a token bucket per client, so one client can not use up the requests to tibia.com of all others
+type clientLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64 // maximum number of tokens
+	buckets   map[string]*clientBucket
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+// newClientLimiter func - creates a clientLimiter allowing perMinute requests per minute with bursts of burst requests
+func newClientLimiter(perMinute, burst int) *clientLimiter {
+	if perMinute < 1 {
+		perMinute = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &clientLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*clientBucket),
+		now:     time.Now,
+	}
+}
+
+// take func - takes a token of the bucket of client if there is one
+func (l *clientLimiter) take(client string) clientLimit {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= clientLimiterSweepInterval {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &clientBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	limit := clientLimit{limit: int(l.burst)}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		limit.allowed = true
+	} else {
+		limit.retryAfter = l.duration(1 - bucket.tokens)
+	}
+
+	limit.remaining = int(bucket.tokens)
+	limit.reset = l.duration(l.burst - bucket.tokens)
+
+	return limit
+}
+
+// duration func - returns how long it takes to accrue tokens
+func (l *clientLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep func - removes the buckets that are full again, the lock must be held
+func (l *clientLimiter) sweep(now time.Time) {
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+
+	l.lastSweep = now
+}
+
+// clientLimiters limits the requests per client IP
+// expensive routes (like the houses of a town, which need a request per house type) have a bucket of their own
+type clientLimiters struct {
+	regular         *clientLimiter
+	expensive       *clientLimiter
+	expensiveRoutes map[string]bool
+}
+
+// newClientLimiters func - creates the clientLimiters of the regular and the expensive routes
+// expensiveRoutes are route patterns like /v4/houses/:world/:town separated by ","
+func newClientLimiters(regular, expensive *clientLimiter, expensiveRoutes string) *clientLimiters {
+	limiters := &clientLimiters{
+		regular:         regular,
+		expensive:       expensive,
+		expensiveRoutes: make(map[string]bool),
+	}
+
+	for _, route := range strings.Split(expensiveRoutes, ",") {
+		if route = strings.TrimSpace(route); route != "" {
+			limiters.expensiveRoutes[route] = true
+		}
+	}
+
+	return limiters
+}
+
+// Middleware func - returns a gin middleware rejecting requests of clients exceeding their limit
+// the client IP honours GIN_TRUSTED_PROXIES, requests of the cache warmer are not limited
+func (l *clientLimiters) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isCacheRefresh(c.Request.Context()) {
+			c.Next()
+			return
+		}
+
+		limiter := l.regular
+		if l.expensive != nil && l.expensiveRoutes[c.FullPath()] {
+			limiter = l.expensive
+		}
+
+		limit := limiter.take(c.ClientIP())
+
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(limit.remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(limit.reset.Seconds()))))
+
+		if !limit.allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limit.retryAfter.Seconds()))))
+			TibiaDataErrorHandler(c, validation.ErrorRateLimitExceeded, http.StatusTooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
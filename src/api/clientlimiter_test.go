@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	limiter := newClientLimiter(60, 2)
+	limiter.now = func() time.Time { return now }
+
+	limit := limiter.take("a")
+	assert.True(limit.allowed)
+	assert.Equal(2, limit.limit)
+	assert.Equal(1, limit.remaining)
+	assert.Equal(time.Second, limit.reset)
+
+	limit = limiter.take("a")
+	assert.True(limit.allowed)
+	assert.Equal(0, limit.remaining)
+	assert.Equal(2*time.Second, limit.reset)
+
+	limit = limiter.take("a")
+	assert.False(limit.allowed)
+	assert.Equal(time.Second, limit.retryAfter)
+
+	// other clients have buckets of their own
+	assert.True(limiter.take("b").allowed)
+
+	// the bucket refills at the rate
+	now = now.Add(time.Second)
+	assert.True(limiter.take("a").allowed)
+	assert.False(limiter.take("a").allowed)
+
+	// full buckets are removed
+	now = now.Add(clientLimiterSweepInterval)
+	limiter.take("c")
+	assert.Len(limiter.buckets, 1)
+}
+
+func TestClientLimitersMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	limiters := newClientLimiters(newClientLimiter(60, 2), newClientLimiter(60, 1), "/v4/houses/:world/:town")
+
+	router := gin.New()
+	_ = router.SetTrustedProxies([]string{"10.0.0.1"})
+	v4 := router.Group("/v4", limiters.Middleware())
+	v4.GET("/houses/:world/:town", func(c *gin.Context) { c.Status(http.StatusOK) })
+	v4.GET("/worlds", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			request.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		router.ServeHTTP(w, request)
+
+		return w
+	}
+
+	w := serve("/v4/worlds", "192.0.2.1:1234", "")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal("1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal("1", w.Header().Get("RateLimit-Reset"))
+
+	// the expensive routes have a bucket of their own
+	w = serve("/v4/houses/Antica/Thais", "192.0.2.1:1234", "")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("1", w.Header().Get("RateLimit-Limit"))
+
+	w = serve("/v4/houses/Antica/Venore", "192.0.2.1:1234", "")
+	assert.Equal(http.StatusTooManyRequests, w.Code)
+	assert.Equal("1", w.Header().Get("Retry-After"))
+
+	var output OutInformation
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(http.StatusTooManyRequests, output.Information.Status.HTTPCode)
+	assert.Equal(15006, output.Information.Status.Error)
+
+	// X-Forwarded-For is used only from trusted proxies
+	w = serve("/v4/worlds", "192.0.2.1:1234", "198.51.100.1")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("0", w.Header().Get("RateLimit-Remaining"))
+
+	w = serve("/v4/worlds", "10.0.0.1:1234", "198.51.100.1")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("1", w.Header().Get("RateLimit-Remaining"))
+
+	// requests of the cache warmer are not limited
+	w = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/v4/houses/Antica/Thais", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+	router.ServeHTTP(w, request.WithContext(withCacheRefresh(request.Context())))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Empty(w.Header().Get("RateLimit-Limit"))
+}
@@ -0,0 +1,546 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds all settings of the TibiaData API
+// the settings are read with this precedence (last wins): defaults, config file, environment variables, command-line flags
+// every setting has a command-line flag named after its path in the config file, like -upstream.max-wait-seconds
+type Config struct {
+	Debug          bool     `json:"debug" env:"DEBUG_MODE" usage:"log much more details"`
+	GinMode        string   `json:"gin_mode" env:"GIN_MODE" usage:"gin mode: release, debug or test"`
+	Host           string   `json:"host" env:"TIBIADATA_HOST" usage:"public host of the API, used in the User-Agent and in links"`
+	Edition        string   `json:"edition" env:"TIBIADATA_EDITION" usage:"edition of the API (defaults to the edition of the build)"`
+	TrustedProxies []string `json:"trusted_proxies" env:"GIN_TRUSTED_PROXIES" usage:"proxies allowed to set X-Forwarded-For, separated by ,"`
+
+	Server    ServerConfig    `json:"server"`
+	Upstream  UpstreamConfig  `json:"upstream"`
+	Proxies   ProxiesConfig   `json:"proxies"`
+	Circuit   CircuitConfig   `json:"circuit"`
+	Cache     CacheConfig     `json:"cache"`
+	APIKeys   APIKeysConfig   `json:"api_keys"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	CORS      CORSConfig      `json:"cors"`
+	Warmer    WarmerConfig    `json:"warmer"`
+}
+
+// ServerConfig holds the settings of the listener and the http server
+type ServerConfig struct {
+	Addr                     string `json:"addr" env:"TIBIADATA_ADDR" usage:"address to listen on"`
+	UnixSocket               string `json:"unix_socket" env:"TIBIADATA_UNIX_SOCKET" usage:"unix socket to listen on instead of the address"`
+	TLSCertFile              string `json:"tls_cert_file" env:"TIBIADATA_TLS_CERT_FILE" usage:"certificate file, enables TLS (reloaded when it changes)"`
+	TLSKeyFile               string `json:"tls_key_file" env:"TIBIADATA_TLS_KEY_FILE" usage:"key file of the certificate"`
+	ReadHeaderTimeoutSeconds int    `json:"read_header_timeout_seconds" env:"TIBIADATA_READ_HEADER_TIMEOUT_SECONDS" usage:"timeout for reading the request headers (0 for none)"`
+	ReadTimeoutSeconds       int    `json:"read_timeout_seconds" env:"TIBIADATA_READ_TIMEOUT_SECONDS" usage:"timeout for reading the request (0 for none)"`
+	WriteTimeoutSeconds      int    `json:"write_timeout_seconds" env:"TIBIADATA_WRITE_TIMEOUT_SECONDS" usage:"timeout for writing the response (0 for none)"`
+	IdleTimeoutSeconds       int    `json:"idle_timeout_seconds" env:"TIBIADATA_IDLE_TIMEOUT_SECONDS" usage:"timeout of idle keep-alive connections (0 for the read timeout)"`
+	ShutdownDelaySeconds     int    `json:"shutdown_delay_seconds" env:"TIBIADATA_SHUTDOWN_DELAY_SECONDS" usage:"how long the server reports not ready before shutting down"`
+	ShutdownTimeoutSeconds   int    `json:"shutdown_timeout_seconds" env:"TIBIADATA_SHUTDOWN_TIMEOUT_SECONDS" usage:"how long in-flight requests may take during shutdown"`
+}
+
+// UpstreamConfig holds the settings of the requests to tibia.com
+type UpstreamConfig struct {
+	Proxy              string  `json:"proxy" env:"TIBIADATA_PROXY" usage:"domain used instead of www.tibia.com"`
+	ProxyProtocol      string  `json:"proxy_protocol" env:"TIBIADATA_PROXY_PROTOCOL" usage:"protocol of the proxy domain: http or https"`
+	MaxConnsPerHost    int     `json:"max_conns_per_host" env:"TIBIADATA_MAX_CONNS_PER_HOST" usage:"maximum connections to tibia.com"`
+	Rate               float64 `json:"rate" env:"TIBIADATA_UPSTREAM_RATE" usage:"requests per second to tibia.com (0 disables the limit, the default)"`
+	Burst              int     `json:"burst" env:"TIBIADATA_UPSTREAM_BURST" usage:"burst of requests to tibia.com"`
+	MaxWaitSeconds     int     `json:"max_wait_seconds" env:"TIBIADATA_UPSTREAM_MAX_WAIT_SECONDS" usage:"longest wait for the rate limit before failing"`
+	Workers            int     `json:"workers" env:"TIBIADATA_UPSTREAM_WORKERS" usage:"concurrent requests to tibia.com (0 disables the limit, defaults to max-conns-per-host)"`
+	InteractiveWorkers int     `json:"interactive_workers" env:"TIBIADATA_UPSTREAM_INTERACTIVE_WORKERS" usage:"workers reserved for the requests of API clients, background jobs never use them (defaults to a quarter of the workers)"`
+	DeadlineSeconds    int     `json:"deadline_seconds" env:"TIBIADATA_UPSTREAM_DEADLINE_SECONDS" usage:"deadline of the requests of a handler to tibia.com"`
+	ServerSaveMinutes  int     `json:"server_save_minutes" env:"TIBIADATA_SERVER_SAVE_MINUTES" usage:"how long tibia.com is offline after the server save, the last successful responses are served meanwhile (0 disables it)"`
+	VCRMode            string  `json:"vcr_mode" env:"TIBIADATA_VCR_MODE" usage:"record or replay the pages of tibia.com"`
+	VCRDir             string  `json:"vcr_dir" env:"TIBIADATA_VCR_DIR" usage:"directory of the recorded pages"`
+}
+
+// ProxiesConfig holds the settings of the upstream proxy pool
+type ProxiesConfig struct {
+	List               string `json:"list" env:"TIBIADATA_PROXIES" secret:"true" usage:"proxies used for the requests to tibia.com, separated by ,"`
+	Selection          string `json:"selection" env:"TIBIADATA_PROXIES_SELECTION" usage:"proxy selection: round-robin or least-latency"`
+	MaxFailures        int    `json:"max_failures" env:"TIBIADATA_PROXIES_MAX_FAILURES" usage:"consecutive failures before a proxy is ejected"`
+	EjectSeconds       int    `json:"eject_seconds" env:"TIBIADATA_PROXIES_EJECT_SECONDS" usage:"how long a proxy stays ejected"`
+	HealthCheckSeconds int    `json:"health_check_seconds" env:"TIBIADATA_PROXIES_HEALTH_CHECK_SECONDS" usage:"interval of the proxy health checks (0 disables them)"`
+}
+
+// CircuitConfig holds the settings of the circuit breaker of tibia.com
+type CircuitConfig struct {
+	FailureThreshold   int `json:"failure_threshold" env:"TIBIADATA_CIRCUIT_FAILURE_THRESHOLD" usage:"consecutive failures opening the circuit"`
+	OpenSeconds        int `json:"open_seconds" env:"TIBIADATA_CIRCUIT_OPEN_SECONDS" usage:"how long the circuit stays open"`
+	MaintenanceSeconds int `json:"maintenance_seconds" env:"TIBIADATA_CIRCUIT_MAINTENANCE_SECONDS" usage:"how long the circuit stays open during maintenance"`
+}
+
+// CacheConfig holds the settings of the response cache
+type CacheConfig struct {
+	Backend          string `json:"backend" env:"TIBIADATA_CACHE" usage:"cache backend: memory or redis (empty disables the cache)"`
+	MaxEntries       int    `json:"max_entries" env:"TIBIADATA_CACHE_MAX_ENTRIES" usage:"maximum entries of the memory cache"`
+	MaxSizeMB        int    `json:"max_size_mb" env:"TIBIADATA_CACHE_MAX_SIZE_MB" usage:"maximum size of the memory cache"`
+	RedisAddr        string `json:"redis_addr" env:"TIBIADATA_CACHE_REDIS_ADDR" usage:"address of redis"`
+	RedisPassword    string `json:"redis_password" env:"TIBIADATA_CACHE_REDIS_PASSWORD" secret:"true" usage:"password of redis"`
+	RedisDB          int    `json:"redis_db" env:"TIBIADATA_CACHE_REDIS_DB" usage:"database of redis"`
+	StaleIfError     bool   `json:"stale_if_error" env:"TIBIADATA_STALE_IF_ERROR" usage:"serve the last successful response when tibia.com fails"`
+	StaleMaxAgeHours int    `json:"stale_max_age_hours" env:"TIBIADATA_STALE_MAX_AGE_HOURS" usage:"how long the last successful responses are kept"`
+	StaleMaxEntries  int    `json:"stale_max_entries" env:"TIBIADATA_STALE_MAX_ENTRIES" usage:"maximum number of last successful responses"`
+	StaleMaxSizeMB   int    `json:"stale_max_size_mb" env:"TIBIADATA_STALE_MAX_SIZE_MB" usage:"maximum size of the last successful responses kept in memory"`
+}
+
+// APIKeysConfig holds the API keys (API keys are disabled without keys)
+type APIKeysConfig struct {
+	File string   `json:"file" env:"TIBIADATA_API_KEYS_FILE" usage:"JSON file with API keys"`
+	Keys []APIKey `json:"keys" env:"TIBIADATA_API_KEYS" usage:"API keys as JSON array"`
+}
+
+// RateLimitConfig holds the settings of the rate limit per client IP
+type RateLimitConfig struct {
+	PerMinute          int      `json:"per_minute" env:"TIBIADATA_RATE_LIMIT_PER_MINUTE" usage:"requests per minute per client (0 disables the limit)"`
+	Burst              int      `json:"burst" env:"TIBIADATA_RATE_LIMIT_BURST" usage:"burst of requests per client (defaults to per-minute)"`
+	ExpensivePerMinute int      `json:"expensive_per_minute" env:"TIBIADATA_RATE_LIMIT_EXPENSIVE_PER_MINUTE" usage:"requests per minute per client to expensive routes (0 disables the separate limit)"`
+	ExpensiveBurst     int      `json:"expensive_burst" env:"TIBIADATA_RATE_LIMIT_EXPENSIVE_BURST" usage:"burst of requests per client to expensive routes"`
+	ExpensiveRoutes    []string `json:"expensive_routes" env:"TIBIADATA_RATE_LIMIT_EXPENSIVE_ROUTES" usage:"expensive routes, separated by ,"`
+}
+
+// CORSConfig holds the CORS policy (CORS is disabled without allowed origins)
+type CORSConfig struct {
+	AllowedOrigins []string `json:"allowed_origins" env:"TIBIADATA_CORS_ALLOWED_ORIGINS" usage:"origins allowed to call the API, separated by ,"`
+	AllowedMethods []string `json:"allowed_methods" env:"TIBIADATA_CORS_ALLOWED_METHODS" usage:"methods allowed in preflight requests, separated by ,"`
+	AllowedHeaders []string `json:"allowed_headers" env:"TIBIADATA_CORS_ALLOWED_HEADERS" usage:"headers allowed in preflight requests, separated by ,"`
+	ExposedHeaders []string `json:"exposed_headers" env:"TIBIADATA_CORS_EXPOSED_HEADERS" usage:"response headers exposed to browsers, separated by ,"`
+	MaxAgeSeconds  int      `json:"max_age_seconds" env:"TIBIADATA_CORS_MAX_AGE_SECONDS" usage:"how long browsers may cache preflight responses"`
+}
+
+// WarmerConfig holds the cache warmer jobs
+type WarmerConfig struct {
+	Jobs []string `json:"jobs" env:"TIBIADATA_WARMER_JOBS" sep:";" usage:"cache warmer jobs in the format \"<cron expression> <path>\", separated by ;"`
+}
+
+// ConfigResponse is the response of the config admin endpoint
+type ConfigResponse struct {
+	Config      Config      `json:"config"`
+	Information Information `json:"information"`
+}
+
+// configRedacted replaces the values of secret settings
+const configRedacted = "[redacted]"
+
+// defaultConfig func - returns the config with the default settings
+func defaultConfig() Config {
+	return Config{
+		GinMode: "release",
+		Server: ServerConfig{
+			Addr:                     ":8080",
+			ReadHeaderTimeoutSeconds: 10,
+			ReadTimeoutSeconds:       30,
+			WriteTimeoutSeconds:      60,
+			IdleTimeoutSeconds:       120,
+			ShutdownDelaySeconds:     5,
+			ShutdownTimeoutSeconds:   30,
+		},
+		Upstream: UpstreamConfig{
+			ProxyProtocol:      "https",
+			MaxConnsPerHost:    16,
+			Rate:               0,
+			Burst:              20,
+			MaxWaitSeconds:     5,
+			Workers:            -1,
+			InteractiveWorkers: -1,
+			DeadlineSeconds:    15,
+			ServerSaveMinutes:  10,
+			VCRDir:             "vcr",
+		},
+		Proxies: ProxiesConfig{
+			Selection:          proxySelectionRoundRobin,
+			MaxFailures:        3,
+			EjectSeconds:       60,
+			HealthCheckSeconds: 15,
+		},
+		Circuit: CircuitConfig{
+			FailureThreshold:   5,
+			OpenSeconds:        30,
+			MaintenanceSeconds: 60,
+		},
+		Cache: CacheConfig{
+			MaxEntries:       10000,
+			MaxSizeMB:        64,
+			RedisAddr:        "localhost:6379",
+			StaleMaxAgeHours: 24,
+			StaleMaxEntries:  10000,
+			StaleMaxSizeMB:   64,
+		},
+		RateLimit: RateLimitConfig{
+			ExpensivePerMinute: 10,
+			ExpensiveBurst:     5,
+			ExpensiveRoutes:    []string{"/v4/houses/:world/:town"},
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "HEAD", "OPTIONS"},
+			AllowedHeaders: []string{"Accept", "Authorization", "If-None-Match", "X-API-Key"},
+			ExposedHeaders: []string{"ETag", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Warning"},
+			MaxAgeSeconds:  600,
+		},
+	}
+}
+
+// configField is a setting of the Config
+type configField struct {
+	path   string // the path in the config file, like upstream.max_wait_seconds
+	env    string
+	usage  string
+	sep    string // the separator of lists
+	secret bool
+	value  reflect.Value
+}
+
+// flagName func - returns the name of the command-line flag of the field
+func (f configField) flagName() string {
+	return strings.ReplaceAll(f.path, "_", "-")
+}
+
+// configFields func - returns the settings of the struct v points to
+func configFields(v reflect.Value, prefix string) []configField {
+	var fields []configField
+
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+
+		path := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		if structField.Type.Kind() == reflect.Struct {
+			fields = append(fields, configFields(v.Field(i).Addr(), path)...)
+			continue
+		}
+
+		sep := structField.Tag.Get("sep")
+		if sep == "" {
+			sep = ","
+		}
+
+		fields = append(fields, configField{
+			path:   path,
+			env:    structField.Tag.Get("env"),
+			usage:  structField.Tag.Get("usage"),
+			sep:    sep,
+			secret: structField.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+
+	return fields
+}
+
+// set func - sets the field to the value of an environment variable or flag
+func (f configField) set(value string) error {
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(value)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		f.value.SetBool(b)
+
+	case reflect.Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		f.value.SetInt(int64(i))
+
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		f.value.SetFloat(n)
+
+	case reflect.Slice:
+		// lists of structs (like the API keys) are JSON arrays
+		if f.value.Type().Elem().Kind() == reflect.Struct {
+			if err := json.Unmarshal([]byte(value), f.value.Addr().Interface()); err != nil {
+				return fmt.Errorf("invalid JSON array: %w", err)
+			}
+			return nil
+		}
+
+		var values []string
+		for _, v := range strings.Split(value, f.sep) {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		f.value.Set(reflect.ValueOf(values))
+
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+
+	return nil
+}
+
+// configFlag is the flag.Value of a setting
+// the values are kept until the config file and the environment variables are read, since flags take precedence
+type configFlag struct {
+	field configField
+	value *string
+}
+
+func (f *configFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f *configFlag) Set(value string) error {
+	f.value = &value
+	return nil
+}
+
+func (f *configFlag) IsBoolFlag() bool {
+	return f.field.value.IsValid() && f.field.value.Kind() == reflect.Bool
+}
+
+// LoadConfig func - reads the config of the command-line arguments, the config file and the environment
+// the config file is set with -config or TIBIADATA_CONFIG_FILE and may be YAML, TOML or JSON
+func LoadConfig(args []string) (*Config, error) {
+	config := defaultConfig()
+	fields := configFields(reflect.ValueOf(&config), "")
+
+	flags := flag.NewFlagSet("tibiadata-api", flag.ContinueOnError)
+	configFile := flags.String("config", getEnv("TIBIADATA_CONFIG_FILE", ""), "config file (YAML, TOML or JSON, env TIBIADATA_CONFIG_FILE)")
+
+	configFlags := make([]*configFlag, 0, len(fields))
+	for _, field := range fields {
+		usage := field.usage
+		if field.env != "" {
+			usage += " (env " + field.env + ")"
+		}
+
+		configFlag := &configFlag{field: field}
+		flags.Var(configFlag, field.flagName(), usage)
+		configFlags = append(configFlags, configFlag)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := config.readFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+
+	for _, field := range fields {
+		if field.env != "" && isEnvExist(field.env) {
+			if err := field.set(getEnv(field.env, "")); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", field.env, err))
+			}
+		}
+	}
+
+	for _, configFlag := range configFlags {
+		if configFlag.value != nil {
+			if err := configFlag.field.set(*configFlag.value); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", configFlag.field.flagName(), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// the worker pool has the size of the connection pool unless it is set
+	if config.Upstream.Workers < 0 {
+		config.Upstream.Workers = config.Upstream.MaxConnsPerHost
+	}
+
+	// a quarter of the workers (at least one) is reserved for the requests of API clients unless it is set
+	if config.Upstream.InteractiveWorkers < 0 {
+		config.Upstream.InteractiveWorkers = config.Upstream.Workers / 4
+		if config.Upstream.InteractiveWorkers < 1 && config.Upstream.Workers > 1 {
+			config.Upstream.InteractiveWorkers = 1
+		}
+	}
+
+	// the burst of the rate limit is a minute of requests unless it is set
+	if config.RateLimit.Burst == 0 {
+		config.RateLimit.Burst = config.RateLimit.PerMinute
+	}
+
+	if config.APIKeys.File != "" {
+		keys, err := loadAPIKeys(config.APIKeys.File)
+		if err != nil {
+			return nil, fmt.Errorf("api_keys.file: %w", err)
+		}
+		config.APIKeys.Keys = append(config.APIKeys.Keys, keys...)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// readFile func - reads the settings of a config file over the current ones
+func (c *Config) readFile(fileName string) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	// YAML and TOML files are converted to JSON, so the json tags of the Config apply to all formats
+	var settings map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(fileName)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	case ".toml":
+		err = toml.Unmarshal(data, &settings)
+	case ".json":
+		err = json.Unmarshal(data, &settings)
+	default:
+		return fmt.Errorf("%s: unsupported config file format %q (use .yaml, .toml or .json)", fileName, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	data, err = json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	return nil
+}
+
+// Validate func - returns all invalid settings of the config
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(path, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]interface{}{path}, args...)...))
+	}
+
+	oneOf := func(path, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		invalid(path, "%q is not one of %q", value, allowed)
+	}
+
+	// numbers are never negative
+	for _, field := range configFields(reflect.ValueOf(c), "") {
+		switch field.value.Kind() {
+		case reflect.Int:
+			if field.value.Int() < 0 {
+				invalid(field.path, "must not be negative")
+			}
+		case reflect.Float64:
+			if field.value.Float() < 0 {
+				invalid(field.path, "must not be negative")
+			}
+		}
+	}
+
+	oneOf("gin_mode", c.GinMode, "release", "debug", "test")
+	oneOf("upstream.proxy_protocol", c.Upstream.ProxyProtocol, "http", "https")
+	oneOf("upstream.vcr_mode", c.Upstream.VCRMode, "", vcrRecord, vcrReplay)
+	oneOf("proxies.selection", c.Proxies.Selection, proxySelectionRoundRobin, proxySelectionLeastLatency)
+	oneOf("cache.backend", c.Cache.Backend, "", "memory", "redis")
+
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		invalid("server.tls_cert_file", "the certificate and the key file must be set together")
+	}
+
+	if c.Proxies.List != "" {
+		if _, err := parseUpstreamProxies(c.Proxies.List); err != nil {
+			invalid("proxies.list", "%s", err)
+		}
+	}
+
+	if len(c.Warmer.Jobs) > 0 {
+		if _, err := parseWarmerJobs(strings.Join(c.Warmer.Jobs, ";")); err != nil {
+			invalid("warmer.jobs", "%s", err)
+		}
+	}
+
+	if _, err := newAPIKeyStore(c.APIKeys.Keys); err != nil {
+		invalid("api_keys.keys", "%s", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redacted func - returns a copy of the config with the values of secret settings replaced
+func (c *Config) Redacted() Config {
+	redacted := *c
+
+	for _, field := range configFields(reflect.ValueOf(&redacted), "") {
+		if field.secret && field.value.Kind() == reflect.String && field.value.String() != "" {
+			field.value.SetString(configRedacted)
+		}
+	}
+
+	// the keys are copied, so the running config is not changed
+	redacted.APIKeys.Keys = make([]APIKey, len(c.APIKeys.Keys))
+	for i, key := range c.APIKeys.Keys {
+		key.Key = configRedacted
+		redacted.APIKeys.Keys[i] = key
+	}
+
+	return redacted
+}
+
+// proxyDomain func - returns the domain replacing https://www.tibia.com/ in request URLs (empty without a proxy)
+func (c *UpstreamConfig) proxyDomain() string {
+	if c.Proxy == "" {
+		return ""
+	}
+
+	return c.ProxyProtocol + "://" + c.Proxy + "/"
+}
+
+// configHandler returns the running config with the secrets redacted
+func (s *webServer) configHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, ConfigResponse{
+		Config: s.config.Redacted(),
+		Information: Information{
+			APIDetails: TibiaDataAPIDetails,
+			Timestamp:  TibiaDataDatetime(""),
+			Status: Status{
+				HTTPCode: http.StatusOK,
+			},
+		},
+	})
+}
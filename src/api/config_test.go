@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfigFile func - writes a config file to a temporary directory
+func writeConfigFile(t *testing.T, name, content string) string {
+	fileName := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fileName, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return fileName
+}
+
+func TestConfigDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	config, err := LoadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal("release", config.GinMode)
+	assert.Equal(16, config.Upstream.MaxConnsPerHost)
+	assert.Equal(16, config.Upstream.Workers)
+	assert.Equal(4, config.Upstream.InteractiveWorkers)
+	assert.Zero(config.Upstream.Rate)
+	assert.Equal(20, config.Upstream.Burst)
+	assert.Equal([]string{"/v4/houses/:world/:town"}, config.RateLimit.ExpensiveRoutes)
+	assert.Empty(config.CORS.AllowedOrigins)
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	assert := assert.New(t)
+
+	yamlFile := writeConfigFile(t, "config.yaml", `
+gin_mode: debug
+upstream:
+  burst: 30
+  rate: 2.5
+cache:
+  backend: memory
+cors:
+  allowed_origins:
+    - https://tools.example.org
+`)
+
+	// the config file overrides the defaults
+	config, err := LoadConfig([]string{"-config", yamlFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("debug", config.GinMode)
+	assert.Equal(30, config.Upstream.Burst)
+	assert.Equal(2.5, config.Upstream.Rate)
+	assert.Equal("memory", config.Cache.Backend)
+	assert.Equal([]string{"https://tools.example.org"}, config.CORS.AllowedOrigins)
+
+	// environment variables override the config file
+	t.Setenv("TIBIADATA_CONFIG_FILE", yamlFile)
+	t.Setenv("TIBIADATA_UPSTREAM_BURST", "40")
+	t.Setenv("TIBIADATA_CORS_ALLOWED_ORIGINS", "https://a.example.org, https://b.example.org")
+
+	config, err = LoadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(40, config.Upstream.Burst)
+	assert.Equal([]string{"https://a.example.org", "https://b.example.org"}, config.CORS.AllowedOrigins)
+
+	// flags override environment variables
+	config, err = LoadConfig([]string{"-upstream.burst", "50", "-debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(50, config.Upstream.Burst)
+	assert.True(config.Debug)
+
+	// TOML and JSON files use the same names
+	tomlFile := writeConfigFile(t, "config.toml", `
+[upstream]
+workers = 4
+
+[warmer]
+jobs = ["*/5 * * * * /v4/worlds", "@hourly /v4/boostablebosses"]
+`)
+
+	config, err = LoadConfig([]string{"-config", tomlFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(4, config.Upstream.Workers)
+	assert.Equal(1, config.Upstream.InteractiveWorkers)
+	assert.Equal([]string{"*/5 * * * * /v4/worlds", "@hourly /v4/boostablebosses"}, config.Warmer.Jobs)
+
+	jsonFile := writeConfigFile(t, "config.json", `{"api_keys":{"keys":[{"name":"team-a","key":"a"}]}}`)
+
+	config, err = LoadConfig([]string{"-config", jsonFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]APIKey{{Name: "team-a", Key: "a"}}, config.APIKeys.Keys)
+}
+
+func TestConfigErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	// unknown settings are rejected
+	_, err := LoadConfig([]string{"-config", writeConfigFile(t, "config.yaml", "upstream:\n  brust: 1\n")})
+	assert.ErrorContains(err, "brust")
+
+	_, err = LoadConfig([]string{"-config", writeConfigFile(t, "config.ini", "")})
+	assert.ErrorContains(err, "unsupported config file format")
+
+	_, err = LoadConfig([]string{"-unknown"})
+	assert.NotNil(err)
+
+	// invalid values name the environment variable or flag
+	t.Setenv("TIBIADATA_UPSTREAM_BURST", "many")
+	_, err = LoadConfig([]string{"-cache.max-entries", "lots"})
+	assert.ErrorContains(err, `TIBIADATA_UPSTREAM_BURST: invalid integer "many"`)
+	assert.ErrorContains(err, `-cache.max-entries: invalid integer "lots"`)
+
+	t.Setenv("TIBIADATA_UPSTREAM_BURST", "")
+
+	// all invalid settings are reported at once
+	_, err = LoadConfig([]string{"-gin-mode", "production", "-circuit.open-seconds", "-1", "-warmer.jobs", "* * * /v4/worlds"})
+	assert.ErrorContains(err, `gin_mode: "production" is not one of`)
+	assert.ErrorContains(err, "circuit.open_seconds: must not be negative")
+	assert.ErrorContains(err, "warmer.jobs:")
+
+	_, err = LoadConfig([]string{"-api-keys.keys", `[{"name":"team-a"}]`})
+	assert.ErrorContains(err, "api_keys.keys: api key team-a has no key")
+}
+
+func TestConfigRedacted(t *testing.T) {
+	assert := assert.New(t)
+
+	config := defaultConfig()
+	config.Cache.RedisPassword = "redis-secret"
+	config.APIKeys.Keys = []APIKey{{Name: "admin", Key: "admin-secret", Admin: true}}
+
+	s := &webServer{config: &config}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	s.configHandler(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	assert.NotContains(w.Body.String(), "redis-secret")
+	assert.NotContains(w.Body.String(), "admin-secret")
+
+	var output ConfigResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(configRedacted, output.Config.Cache.RedisPassword)
+	assert.Equal(configRedacted, output.Config.APIKeys.Keys[0].Key)
+	assert.Equal("admin", output.Config.APIKeys.Keys[0].Name)
+
+	// empty secrets stay empty and the running config is not changed
+	assert.Empty(output.Config.Proxies.List)
+	assert.Equal("redis-secret", config.Cache.RedisPassword)
+	assert.Equal("admin-secret", config.APIKeys.Keys[0].Key)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsPolicy decides which browser origins may call the API
+type corsPolicy struct {
+	origins        []string // allowed origins, "*" for all, "https://*.example.com" for subdomains
+	methods        string
+	headers        string
+	exposedHeaders string
+	maxAge         time.Duration
+	prefix         string // the path prefix answering preflight requests
+}
+
+// newCORSPolicy func - creates a corsPolicy from comma separated lists
+func newCORSPolicy(origins, methods, headers, exposedHeaders string, maxAge time.Duration) *corsPolicy {
+	policy := &corsPolicy{
+		methods:        corsList(methods),
+		headers:        corsList(headers),
+		exposedHeaders: corsList(exposedHeaders),
+		maxAge:         maxAge,
+		prefix:         "/v4/",
+	}
+
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			policy.origins = append(policy.origins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+
+	return policy
+}
+
+// corsList func - normalizes a comma separated list for a header
+func corsList(list string) string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// allowOrigin func - returns the Access-Control-Allow-Origin value of origin (empty if it is not allowed)
+func (p *corsPolicy) allowOrigin(origin string) string {
+	for _, allowed := range p.origins {
+		switch {
+		case allowed == "*":
+			return "*"
+		case strings.EqualFold(allowed, origin):
+			return origin
+		case strings.Contains(allowed, "://*."):
+			scheme, domain, _ := strings.Cut(allowed, "://*")
+			if strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(strings.ToLower(origin), strings.ToLower(domain)) {
+				return origin
+			}
+		}
+	}
+
+	return ""
+}
+
+// Middleware func - returns a gin middleware adding the CORS headers and answering preflight requests
+// it must run before the rate limit and API key middlewares, since browsers send no credentials with preflight requests
+func (p *corsPolicy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+
+		allowOrigin := p.allowOrigin(origin)
+		if allowOrigin == "" {
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", allowOrigin)
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" && strings.HasPrefix(c.Request.URL.Path, p.prefix) {
+			c.Header("Access-Control-Allow-Methods", p.methods)
+			if p.headers != "" {
+				c.Header("Access-Control-Allow-Headers", p.headers)
+			}
+			if p.maxAge > 0 {
+				c.Header("Access-Control-Max-Age", strconv.Itoa(int(p.maxAge.Seconds())))
+			}
+
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if p.exposedHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", p.exposedHeaders)
+		}
+
+		c.Next()
+	}
+}
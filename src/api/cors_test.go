@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSAllowOrigin(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := newCORSPolicy("https://tools.example.org/, https://*.example.com", "", "", "", 0)
+
+	assert.Equal("https://tools.example.org", policy.allowOrigin("https://tools.example.org"))
+	assert.Equal("https://a.example.com", policy.allowOrigin("https://a.example.com"))
+	assert.Empty(policy.allowOrigin("http://a.example.com"))
+	assert.Empty(policy.allowOrigin("https://evil-example.com"))
+	assert.Empty(policy.allowOrigin("https://example.org"))
+
+	assert.Equal("*", newCORSPolicy("*", "", "", "", 0).allowOrigin("https://example.org"))
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := newAPIKeyStore([]APIKey{{Name: "tools", Key: "tools-key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(newCORSPolicy("https://tools.example.org", "GET,OPTIONS", "X-API-Key", "ETag", 10*time.Minute).Middleware())
+	v4 := router.Group("/v4", store.Middleware())
+	v4.GET("/worlds", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(method, origin string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(method, "/v4/worlds", nil)
+		for name, values := range header {
+			request.Header[name] = values
+		}
+		if origin != "" {
+			request.Header.Set("Origin", origin)
+		}
+		router.ServeHTTP(w, request)
+
+		return w
+	}
+
+	// preflight requests are answered without API key
+	w := serve(http.MethodOptions, "https://tools.example.org", http.Header{"Access-Control-Request-Method": {"GET"}})
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Equal("https://tools.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal("GET, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal("X-API-Key", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal("600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal("Origin", w.Header().Get("Vary"))
+
+	// the headers are added to responses and errors
+	w = serve(http.MethodGet, "https://tools.example.org", http.Header{"X-Api-Key": {"tools-key"}})
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("https://tools.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal("ETag", w.Header().Get("Access-Control-Expose-Headers"))
+
+	w = serve(http.MethodGet, "https://tools.example.org", nil)
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("https://tools.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// other origins get no CORS headers
+	w = serve(http.MethodOptions, "https://example.org", http.Header{"Access-Control-Request-Method": {"GET"}})
+	assert.NotEqual(http.StatusNoContent, w.Code)
+	assert.Empty(w.Header().Get("Access-Control-Allow-Origin"))
+
+	// requests without origin are not changed
+	w = serve(http.MethodGet, "", http.Header{"X-Api-Key": {"tools-key"}})
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Empty(w.Header().Get("Vary"))
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule returns the next time a job should run after a given time
+type cronSchedule interface {
+	Next(after time.Time) time.Time
+}
+
+// cronAliases are the predefined cron expressions
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCronSchedule func - parses a cron expression with the fields minute, hour, day of month, month and day of week
+// the fields support *, lists (1,2), ranges (1-5) and steps (*/5, 1-30/2)
+// the aliases @hourly, @daily etc. and intervals like "@every 5m" are supported as well
+// times are evaluated in the timezone of tibia.com (CET/CEST)
+func parseCronSchedule(expr string) (cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if interval, ok := strings.CutPrefix(expr, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || every <= 0 {
+			return nil, fmt.Errorf("invalid interval in cron expression %q", expr)
+		}
+		return everySchedule(every), nil
+	}
+
+	if alias, ok := cronAliases[expr]; ok {
+		expr = alias
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expr)
+	}
+
+	var (
+		s   = &fieldSchedule{location: TibiaDataServerSave.location}
+		err error
+	)
+
+	if s.minute, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minute of cron expression %q: %w", expr, err)
+	}
+	if s.hour, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hour of cron expression %q: %w", expr, err)
+	}
+	if s.dom, s.domStar, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("day of month of cron expression %q: %w", expr, err)
+	}
+	if s.month, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("month of cron expression %q: %w", expr, err)
+	}
+	if s.dow, s.dowStar, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("day of week of cron expression %q: %w", expr, err)
+	}
+
+	// 7 is sunday as well
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+// parseCronField func - returns the bits of the values of a field and whether it is *
+func parseCronField(field string, min, max int) (uint64, bool, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, false, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		start, end := min, max
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if start, err = strconv.Atoi(startPart); err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", startPart)
+			}
+
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endPart); err != nil {
+					return 0, false, fmt.Errorf("invalid value %q", endPart)
+				}
+			} else if hasStep {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, false, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, field == "*", nil
+}
+
+// fieldSchedule is a cronSchedule of a cron expression
+type fieldSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+	location                      *time.Location
+}
+
+// Next func - returns the first matching minute after after
+func (s *fieldSchedule) Next(after time.Time) time.Time {
+	t := after.In(s.location)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, s.location)
+
+	// an expression like "0 0 30 2 *" never matches
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches func - reports whether the day matches the day of month or the day of week
+// like cron, a day matches either of them if both are restricted
+func (s *fieldSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// everySchedule is a cronSchedule with a fixed interval
+type everySchedule time.Duration
+
+// Next func - returns after plus the interval
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
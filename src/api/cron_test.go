@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	berlin := TibiaDataServerSave.location
+	after := time.Date(2023, 7, 1, 10, 2, 30, 0, berlin) // a saturday
+
+	next := func(expr string) time.Time {
+		schedule, err := parseCronSchedule(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return schedule.Next(after)
+	}
+
+	assert.Equal(time.Date(2023, 7, 1, 10, 3, 0, 0, berlin), next("* * * * *"))
+	assert.Equal(time.Date(2023, 7, 1, 10, 5, 0, 0, berlin), next("*/5 * * * *"))
+	assert.Equal(time.Date(2023, 7, 2, 10, 1, 0, 0, berlin), next("1 10 * * *"))
+	assert.Equal(time.Date(2023, 7, 1, 11, 0, 0, 0, berlin), next("@hourly"))
+	assert.Equal(time.Date(2023, 7, 3, 0, 0, 0, 0, berlin), next("0 0 * * 1-5"))
+	assert.Equal(time.Date(2023, 7, 2, 0, 0, 0, 0, berlin), next("0 0 * * 7"))
+	assert.Equal(time.Date(2023, 8, 1, 0, 15, 0, 0, berlin), next("15,45 0 1 8 *"))
+	assert.Equal(after.Add(90*time.Second), next("@every 90s"))
+
+	// day of month and day of week match either of them
+	assert.Equal(time.Date(2023, 7, 3, 0, 0, 0, 0, berlin), next("0 0 15 * 1"))
+
+	// never matches
+	assert.True(next("0 0 30 2 *").IsZero())
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every soon", "@every -1m"} {
+		_, err := parseCronSchedule(expr)
+		assert.NotNil(err, expr)
+	}
+}
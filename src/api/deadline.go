@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TibiaDataDefaultDeadlineBudget is how long the upstream requests of a handler may take in total
+// including waiting for the upstream limiter and retries, routers use TIBIADATA_UPSTREAM_DEADLINE_SECONDS instead
+const TibiaDataDefaultDeadlineBudget = 15 * time.Second
+
+// TibiaDataDeadlineBudgets holds the deadline budget of handlers needing more than the default
+var TibiaDataDeadlineBudgets = map[string]time.Duration{
+	"TibiaHousesOverview": 30 * time.Second, // houses and guildhalls are two requests
+}
+
+// deadlineBudget func - returns the deadline budget of a handler name
+func (s *webServer) deadlineBudget(handlerName string) time.Duration {
+	if budget, ok := TibiaDataDeadlineBudgets[handlerName]; ok {
+		return budget
+	}
+
+	if s.defaultDeadline > 0 {
+		return s.defaultDeadline
+	}
+
+	return TibiaDataDefaultDeadlineBudget
+}
+
+// flight is the context of a request coalesced by webServer.requests
+// it is cancelled once all callers are gone or its deadline budget is used up
+// its upstream requests have the highest priority of the callers
+type flight struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	priority *sharedUpstreamPriority
+	callers  int
+}
+
+// flights holds the flights that are still running by key
+type flights struct {
+	mu      sync.Mutex
+	running map[string]*flight
+}
+
+// joinFlight func - registers a caller of key with priority and returns the flight shared with the other callers
+// a caller with a higher priority raises the priority of the flight (e.g. a client joining the cache warmer)
+func (s *webServer) joinFlight(key string, budget time.Duration, priority upstreamPriority) *flight {
+	s.flights.mu.Lock()
+	defer s.flights.mu.Unlock()
+
+	if s.flights.running == nil {
+		s.flights.running = make(map[string]*flight)
+	}
+
+	f, ok := s.flights.running[key]
+	if !ok {
+		f = &flight{priority: newSharedUpstreamPriority(priority)}
+		f.ctx, f.cancel = context.WithTimeout(withSharedUpstreamPriority(context.Background(), f.priority), budget)
+		s.flights.running[key] = f
+	}
+	f.priority.Raise(priority)
+	f.callers++
+
+	return f
+}
+
+// landFlight func - marks the flight of key as finished
+// callers arriving afterwards start a new flight with a fresh deadline budget
+func (s *webServer) landFlight(key string, f *flight) {
+	s.flights.mu.Lock()
+	defer s.flights.mu.Unlock()
+
+	if s.flights.running[key] == f {
+		delete(s.flights.running, key)
+	}
+}
+
+// leaveFlight func - unregisters a caller of key
+// the last caller leaving cancels the flight (e.g. when all clients went away)
+func (s *webServer) leaveFlight(key string, f *flight) {
+	s.flights.mu.Lock()
+	defer s.flights.mu.Unlock()
+
+	f.callers--
+	if f.callers > 0 {
+		return
+	}
+
+	f.cancel()
+
+	// the next caller must not join the cancelled request
+	if s.flights.running[key] == f {
+		delete(s.flights.running, key)
+		s.requests.Forget(key)
+	}
+}
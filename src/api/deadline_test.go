@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadlineBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &webServer{}
+	assert.Equal(TibiaDataDefaultDeadlineBudget, s.deadlineBudget("TibiaWorldsOverview"))
+	assert.Equal(30*time.Second, s.deadlineBudget("TibiaHousesOverview"))
+
+	s = &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}),
+		defaultDeadline: 50 * time.Millisecond,
+	}
+	assert.Equal(50*time.Millisecond, s.deadlineBudget("TibiaWorldsOverview"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return nil, errors.New("not reached")
+	}
+
+	start := time.Now()
+	s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+	assert.Equal(http.StatusGatewayTimeout, w.Code)
+	assert.Less(time.Since(start), time.Second)
+}
+
+func TestClientCancellation(t *testing.T) {
+	assert := assert.New(t)
+
+	fetchErr := make(chan error, 1)
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			<-ctx.Done()
+			fetchErr <- ctx.Err()
+			return "", ctx.Err()
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil).WithContext(ctx)
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return nil, errors.New("not reached")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+	assert.True(c.IsAborted())
+	assert.Empty(w.Body.String())
+
+	// the upstream request was cancelled with the last client waiting for it
+	select {
+	case err := <-fetchErr:
+		assert.ErrorIs(err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("upstream request was not cancelled")
+	}
+
+	// the next caller starts a new request
+	s.flights.mu.Lock()
+	assert.Empty(s.flights.running)
+	s.flights.mu.Unlock()
+}
+
+func TestFlightPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &webServer{}
+
+	// the cache warmer starts the flight with the background priority
+	f := s.joinFlight("key", time.Minute, upstreamPriorityBackground)
+	defer f.cancel()
+	assert.Equal(upstreamPriorityBackground, upstreamPriorityFromContext(f.ctx))
+
+	// a client joining raises the priority of the requests still waiting for a worker
+	assert.Same(f, s.joinFlight("key", time.Minute, upstreamPriorityInteractive))
+	assert.Equal(upstreamPriorityInteractive, upstreamPriorityFromContext(f.ctx))
+
+	// another background caller does not lower it
+	assert.Same(f, s.joinFlight("key", time.Minute, upstreamPriorityBackground))
+	assert.Equal(upstreamPriorityInteractive, upstreamPriorityFromContext(f.ctx))
+	assert.Equal(3, f.callers)
+}
@@ -1,10 +1,9 @@
-package main
+package api
 
 import (
 	"log"
 	"net/http"
 
-	"github.com/TibiaData/tibiadata-api-go/src/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/go-resty/resty/v2"
 )
@@ -30,6 +29,19 @@ type Debug struct {
 	BiggestSpellNameOrFormulaRuneCount  int    `json:"biggest_spell_name_or_formula_rune_count"`
 	SmallestSpellWordRuneCount          int    `json:"smallest_spell_word_rune_count"`
 	BiggestSpellWordRuneCount           int    `json:"biggest_spell_word_rune_count"`
+
+	// Runtime information
+	RuntimeStatus
+}
+
+// RuntimeStatus stores the state of the cache, tibia.com and the background jobs
+type RuntimeStatus struct {
+	Cache    *CacheStats            `json:"cache,omitempty"`
+	Stale    *CacheStats            `json:"stale,omitempty"`
+	Upstream *UpstreamStatus        `json:"upstream,omitempty"`
+	Proxies  []ProxyStatus          `json:"proxies,omitempty"`
+	Workers  *UpstreamWorkersStatus `json:"workers,omitempty"`
+	Warmer   []WarmerJobStatus      `json:"warmer,omitempty"`
 }
 
 // TibiaDataRequestTraceLogger func - prints out trace information to log
@@ -53,7 +65,7 @@ func TibiaDataRequestTraceLogger(res *resty.Response, err error) {
 }
 
 // debugHandler returns some debug information
-func debugHandler(c *gin.Context) {
+func (s *webServer) debugHandler(c *gin.Context) {
 	data := Information{
 		APIDetails: TibiaDataAPIDetails,
 		Timestamp:  TibiaDataDatetime(""),
@@ -66,121 +78,165 @@ func debugHandler(c *gin.Context) {
 	debug := Debug{
 		TibiaDataUserAgent: TibiaDataUserAgent,
 	}
+	validator := s.validation()
 
 	// Shas
-	sha256, err := validation.GetSha256Sum()
+	sha256, err := validator.GetSha256Sum()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.DataSha256Sum = sha256
 
-	sha512, err := validation.GetSha512Sum()
+	sha512, err := validator.GetSha512Sum()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.DataSha512Sum = sha512
 
 	// Creatures
-	smallestCreatureName, err := validation.GetSmallestCreatureName()
+	smallestCreatureName, err := validator.GetSmallestCreatureName()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestCreatureName = smallestCreatureName
 
-	biggestCreatureName, err := validation.GetBiggestCreatureName()
+	biggestCreatureName, err := validator.GetBiggestCreatureName()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestCreatureName = biggestCreatureName
 
-	biggestCreatureWord, err := validation.GetBiggestCreatureWord()
+	biggestCreatureWord, err := validator.GetBiggestCreatureWord()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestCreatureWord = biggestCreatureWord
 
-	smallestCreatureWord, err := validation.GetSmallestCreatureWord()
+	smallestCreatureWord, err := validator.GetSmallestCreatureWord()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestCreatureWord = smallestCreatureWord
 
-	smallestCreatureNameRuneCount, err := validation.GetSmallestCreatureNameRuneCount()
+	smallestCreatureNameRuneCount, err := validator.GetSmallestCreatureNameRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestCreatureNameRuneCount = smallestCreatureNameRuneCount
 
-	biggestCreatureNameRuneCount, err := validation.GetBiggestCreatureNameRuneCount()
+	biggestCreatureNameRuneCount, err := validator.GetBiggestCreatureNameRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestCreatureNameRuneCount = biggestCreatureNameRuneCount
 
-	smallestCreatureWordRuneCount, err := validation.GetSmallestCreatureWordRuneCount()
+	smallestCreatureWordRuneCount, err := validator.GetSmallestCreatureWordRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestCreatureWordRuneCount = smallestCreatureWordRuneCount
 
-	biggestCreatureWordRuneCount, err := validation.GetBiggestCreatureWordRuneCount()
+	biggestCreatureWordRuneCount, err := validator.GetBiggestCreatureWordRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestCreatureWordRuneCount = biggestCreatureWordRuneCount
 
 	// Spells
-	smallestSpellName, err := validation.GetSmallestSpellNameOrFormula()
+	smallestSpellName, err := validator.GetSmallestSpellNameOrFormula()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestSpellNameOrFormula = smallestSpellName
 
-	biggestSpellName, err := validation.GetBiggestSpellNameOrFormula()
+	biggestSpellName, err := validator.GetBiggestSpellNameOrFormula()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestSpellNameOrFormula = biggestSpellName
 
-	biggestSpellWord, err := validation.GetBiggestSpellWord()
+	biggestSpellWord, err := validator.GetBiggestSpellWord()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestSpellWord = biggestSpellWord
 
-	smallestSpellWord, err := validation.GetSmallestSpellWord()
+	smallestSpellWord, err := validator.GetSmallestSpellWord()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestSpellWord = smallestSpellWord
 
-	smallestSpellNameRuneCount, err := validation.GetSmallestSpellNameOrFormulaRuneCount()
+	smallestSpellNameRuneCount, err := validator.GetSmallestSpellNameOrFormulaRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestSpellNameOrFormulaRuneCount = smallestSpellNameRuneCount
 
-	biggestSpellNameRuneCount, err := validation.GetBiggestSpellNameOrFormulaRuneCount()
+	biggestSpellNameRuneCount, err := validator.GetBiggestSpellNameOrFormulaRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestSpellNameOrFormulaRuneCount = biggestSpellNameRuneCount
 
-	smallestSpellWordRuneCount, err := validation.GetSmallestSpellWordRuneCount()
+	smallestSpellWordRuneCount, err := validator.GetSmallestSpellWordRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.SmallestSpellWordRuneCount = smallestSpellWordRuneCount
 
-	biggestSpellWordRuneCount, err := validation.GetBiggestSpellWordRuneCount()
+	biggestSpellWordRuneCount, err := validator.GetBiggestSpellWordRuneCount()
 	if err != nil {
 		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
 	}
 	debug.BiggestSpellWordRuneCount = biggestSpellWordRuneCount
 
+	debug.RuntimeStatus = s.runtimeStatus()
+
 	var output DebugOutInformation
 	output.Information = data
 	output.Debug = debug
 
 	c.JSON(http.StatusOK, output)
 }
+
+// runtimeStatus func - returns the state of the cache, tibia.com and the background jobs
+func (s *webServer) runtimeStatus() RuntimeStatus {
+	var status RuntimeStatus
+
+	// Cache
+	if s.cache != nil {
+		cacheStats := s.cache.Stats()
+		status.Cache = &cacheStats
+	}
+
+	// Last successful responses
+	if s.stale != nil {
+		staleStats := s.stale.Stats()
+		status.Stale = &staleStats
+	}
+
+	// Upstream
+	if s.circuit != nil {
+		upstreamStatus := s.circuit.Status()
+		status.Upstream = &upstreamStatus
+	}
+
+	// Proxies
+	if s.proxies != nil {
+		status.Proxies = s.proxies.Status()
+	}
+
+	// Workers
+	if s.workers != nil {
+		workersStatus := s.workers.Status()
+		status.Workers = &workersStatus
+	}
+
+	// Cache warmer
+	if s.warmer != nil {
+		status.Warmer = s.warmer.Status()
+	}
+
+	return status
+}
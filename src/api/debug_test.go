@@ -1,4 +1,4 @@
-package main
+package api
 
 import (
 	"net/http"
@@ -17,7 +17,8 @@ func TestDebugHandler(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 
-	debugHandler(c)
+	s := &webServer{cache: newMemoryCache(10, 0)}
+	s.debugHandler(c)
 
 	assert.Equal(http.StatusOK, w.Code)
 }
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// tibiaDataETag func - returns a weak ETag of a serialized response
+// the information block is left out, since its timestamp, cache age and server save details change
+// with every response while the data stays the same
+func tibiaDataETag(data []byte) string {
+	hash := sha256.New()
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(data, &response); err != nil {
+		hash.Write(data)
+	} else {
+		keys := make([]string, 0, len(response))
+		for key := range response {
+			if key != "information" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			hash.Write([]byte(key))
+			hash.Write(response[key])
+		}
+	}
+
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// tibiaDataETagMatches func - reports whether the If-None-Match header matches etag
+// the weak comparison is used, so W/"x" matches "x"
+func tibiaDataETagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	assert := assert.New(t)
+
+	etag := tibiaDataETag([]byte(`{"worlds":{"players_online":1},"information":{"timestamp":"2023-06-01T10:00:00Z"}}`))
+	assert.Regexp(`^W/"[0-9a-f]{32}"$`, etag)
+
+	// the information block does not change the ETag
+	assert.Equal(etag, tibiaDataETag([]byte(`{"information":{"timestamp":"2023-06-01T10:05:00Z","cache":{"hit":true}},"worlds":{"players_online":1}}`)))
+
+	// the data does
+	assert.NotEqual(etag, tibiaDataETag([]byte(`{"worlds":{"players_online":2},"information":{"timestamp":"2023-06-01T10:00:00Z"}}`)))
+
+	// invalid JSON is hashed as is
+	assert.NotEqual(tibiaDataETag([]byte(`abc`)), tibiaDataETag([]byte(`abd`)))
+}
+
+func TestETagMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	etag := `W/"abc"`
+
+	assert.False(tibiaDataETagMatches("", etag))
+	assert.False(tibiaDataETagMatches(`"abd"`, etag))
+	assert.True(tibiaDataETagMatches(`W/"abc"`, etag))
+	assert.True(tibiaDataETagMatches(`"abc"`, etag))
+	assert.True(tibiaDataETagMatches(`"abd", W/"abc"`, etag))
+	assert.True(tibiaDataETagMatches(`*`, etag))
+}
+
+func TestNotModified(t *testing.T) {
+	assert := assert.New(t)
+
+	fetches := 0
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			fetches++
+			return "content", nil
+		}),
+		cache: newMemoryCache(10, 0),
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return OutInformation{Information: Information{Status: Status{HTTPCode: http.StatusOK}}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+	s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+	assert.Equal(http.StatusOK, w.Code)
+
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(etag)
+
+	// the cached response has another information block, but the same ETag
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+	c.Request.Header.Set("If-None-Match", etag)
+
+	s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+	assert.Equal(http.StatusNotModified, w.Code)
+	assert.Equal(etag, w.Header().Get("ETag"))
+	assert.Empty(w.Body.String())
+
+	// a stale ETag gets the full response
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+	c.Request.Header.Set("If-None-Match", `W/"stale"`)
+
+	s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.NotEmpty(w.Body.String())
+
+	assert.Equal(1, fetches)
+}
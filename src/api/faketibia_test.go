@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/faketibia"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTibiaEndToEnd(t *testing.T) {
+	assert := assert.New(t)
+
+	fake, err := faketibia.NewFromTestFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	// the requests go through the fake server like through TIBIADATA_PROXY
+	s := &webServer{
+		fetcher: newTibiaDataFetcher(server.URL+"/", 2),
+		circuit: newUpstreamCircuit(3, time.Minute, time.Minute),
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/character/Torbjörn", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "Torbjörn"}}
+
+	s.tibiaCharactersCharacter(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	var character CharacterResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &character); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("Torbjörn", character.Character.CharacterInfo.Name)
+
+	// maintenance of tibia.com opens the circuit
+	fake.SetMaintenance(true)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+	s.tibiaWorldsOverview(c)
+	assert.Equal(http.StatusServiceUnavailable, w.Code)
+	assert.Equal("60", w.Header().Get("Retry-After"))
+	assert.Contains(w.Body.String(), validation.ErrorMaintenanceMode.Error())
+}
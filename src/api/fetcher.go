@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/go-resty/resty/v2"
+)
+
+// Fetcher retrieves the box content html of a page on tibia.com
+// the request is abandoned once ctx is done
+type Fetcher interface {
+	Fetch(ctx context.Context, TibiaDataRequest TibiaDataRequestStruct) (string, error)
+}
+
+// FetcherFunc is an adapter to allow the use of ordinary functions as Fetcher
+type FetcherFunc func(ctx context.Context, TibiaDataRequest TibiaDataRequestStruct) (string, error)
+
+// Fetch calls f(ctx, TibiaDataRequest)
+func (f FetcherFunc) Fetch(ctx context.Context, TibiaDataRequest TibiaDataRequestStruct) (string, error) {
+	return f(ctx, TibiaDataRequest)
+}
+
+// tibiaDataQueueError wraps errors of requests given up before they were sent to tibia.com
+// because no worker was free or the upstream limiter would wait too long
+// they say nothing about the state of tibia.com, so they are not recorded by the upstreamCircuit
+type tibiaDataQueueError struct {
+	err error
+}
+
+func (e tibiaDataQueueError) Error() string {
+	return e.err.Error()
+}
+
+func (e tibiaDataQueueError) Unwrap() error {
+	return e.err
+}
+
+// tibiaDataFetcher is the default Fetcher sending requests to tibia.com
+// all requests share one resty client, so connections are pooled and reused
+type tibiaDataFetcher struct {
+	client      *resty.Client
+	proxyDomain string              // replaces https://www.tibia.com/ in request URLs if set
+	proxies     *proxyPool          // distributes requests over multiple proxies if set
+	limiter     *upstreamLimiter    // limits the rate of requests if set
+	workers     *upstreamWorkerPool // limits the number of concurrent requests by priority if set
+	debug       *atomic.Bool        // the debug mode of the router using the fetcher (TibiaDataDebug by default)
+	charset     *tibiaDataCharset   // the charset of the last page of tibia.com, queries are encoded in it
+}
+
+// newTibiaDataFetcher func - creates a tibiaDataFetcher with a pooled http transport
+func newTibiaDataFetcher(proxyDomain string, maxConnsPerHost int) *tibiaDataFetcher {
+	// Setting up the shared transport with limits per host
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   maxConnsPerHost,
+		MaxConnsPerHost:       maxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	// Setting up resty client
+	client := resty.NewWithClient(&http.Client{Transport: transport})
+
+	// Set client timeout  and retry
+	client.SetTimeout(5 * time.Second)
+	client.SetRetryCount(2)
+
+	// Set headers for all requests
+	client.SetHeaders(map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   TibiaDataUserAgent,
+	})
+
+	// Enabling Content length value for all request
+	client.SetContentLength(true)
+
+	// Disable redirection of client (so we skip parsing maintenance page)
+	client.SetRedirectPolicy(resty.NoRedirectPolicy())
+
+	fetcher := &tibiaDataFetcher{
+		client:      client,
+		proxyDomain: proxyDomain,
+		charset:     newTibiaDataCharset(),
+	}
+	fetcher.setDebug(&TibiaDataDebug)
+
+	return fetcher
+}
+
+// setDebug func - makes the fetcher follow the debug mode debug
+func (f *tibiaDataFetcher) setDebug(debug *atomic.Bool) {
+	f.debug = debug
+
+	// Set Debug if enabled on startup
+	f.client.SetDebug(debug.Load())
+	if debug.Load() {
+		f.client.EnableTrace()
+	} else {
+		f.client.DisableTrace()
+	}
+}
+
+// Fetch func - makes the request to tibia.com and returns the box content html
+// ctx covers the whole request including waiting for the limiter and retries
+func (f *tibiaDataFetcher) Fetch(ctx context.Context, TibiaDataRequest TibiaDataRequestStruct) (string, error) {
+	// Wait for a worker unless env TIBIADATA_UPSTREAM_WORKERS is 0
+	// the worker is held while waiting for the limiter, so the tokens go to the requests with the highest priority
+	if f.workers != nil {
+		release, err := f.workers.Acquire(ctx)
+		if err != nil {
+			log.Printf("[warning] TibiaDataFetcher (URL: %s): no worker available: %s", TibiaDataRequest.URL, err)
+			return "", tibiaDataQueueError{err}
+		}
+		defer release()
+	}
+
+	// Wait for the upstream limiter if env TIBIADATA_UPSTREAM_RATE set
+	if f.limiter != nil {
+		if err := f.limiter.Wait(ctx); err != nil {
+			log.Printf("[warning] TibiaDataFetcher (URL: %s): %s", TibiaDataRequest.URL, err)
+			return "", tibiaDataQueueError{err}
+		}
+	}
+
+	request := f.client.R().SetContext(ctx)
+
+	// Replace domain with a proxy of the pool if env TIBIADATA_PROXIES set
+	// or with the proxy if env TIBIADATA_PROXY set
+	var proxy *upstreamProxy
+	if f.proxies != nil {
+		proxy = f.proxies.Next()
+		TibiaDataRequest.URL = strings.ReplaceAll(TibiaDataRequest.URL, "https://www.tibia.com/", proxy.baseURL)
+		if proxy.headerName != "" {
+			request.SetHeader(proxy.headerName, proxy.headerValue)
+		}
+	} else if f.proxyDomain != "" {
+		TibiaDataRequest.URL = strings.ReplaceAll(TibiaDataRequest.URL, "https://www.tibia.com/", f.proxyDomain)
+	}
+
+	// defining values for request
+	var (
+		res        *resty.Response
+		err        error
+		LogMessage string
+	)
+
+	// tracing the request if debug was enabled at runtime
+	if f.debug.Load() {
+		request.EnableTrace()
+	}
+
+	start := time.Now()
+
+	switch TibiaDataRequest.Method {
+	case resty.MethodPost:
+		res, err = request.
+			SetFormData(TibiaDataRequest.FormData).
+			Post(TibiaDataRequest.URL)
+	default:
+		res, err = request.Get(TibiaDataRequest.URL)
+	}
+
+	// Update the health of the proxy (redirects of tibia.com and cancelled requests are not failures of the proxy)
+	if proxy != nil && !errors.Is(err, context.Canceled) {
+		failed := res == nil || res.StatusCode() == 0 || res.StatusCode() == http.StatusForbidden || res.StatusCode() >= http.StatusInternalServerError
+		f.proxies.Record(proxy, time.Since(start), failed)
+	}
+
+	if f.debug.Load() {
+		// logging trace information for resty
+		TibiaDataRequestTraceLogger(res, err)
+	}
+
+	// throttled request (tibia.com answers with 403 without a request error)
+	if res != nil && res.StatusCode() == http.StatusForbidden {
+		LogMessage = "request throttled due to rate-limitation on tibia.com"
+		log.Printf("[warning] TibiaDataFetcher: %s!", LogMessage)
+
+		return "", f.throttled()
+	}
+
+	if err != nil {
+		log.Printf("[error] TibiaDataFetcher (Status: %s, URL: %s) in resp1: %s", res.Status(), res.Request.URL, err)
+
+		switch res.StatusCode() {
+		case http.StatusFound:
+			// Check if page is in maintenance mode
+			location, _ := res.RawResponse.Location()
+			if location.Host == "maintenance.tibia.com" {
+				LogMessage := "maintenance mode detected on tibia.com"
+				log.Printf("[info] TibiaDataFetcher: %s!", LogMessage)
+				return "", validation.ErrorMaintenanceMode
+			}
+			fallthrough
+
+		default:
+			LogMessage = "unknown error occurred on tibia.com"
+			log.Printf("[error] TibiaDataFetcher: %s!", LogMessage)
+			return "", err
+		}
+	}
+
+	// wrap body in a converting reader from the charset of the page (usually ISO 8859-1) to UTF-8
+	// the charset is kept, so the queries of the next requests are encoded in it as well
+	charsetName, charsetEncoding := tibiaDataResponseCharset(res.Header().Get("Content-Type"), res.Body())
+	f.charset.set(charsetName, charsetEncoding)
+	resIo2 := tibiaDataConvertToUTF8(charsetEncoding, res.Body())
+
+	// Load the HTML document
+	doc, err := goquery.NewDocumentFromReader(resIo2)
+	if err != nil {
+		log.Printf("[error] TibiaDataFetcher (URL: %s) error: %s", res.Request.URL, err)
+		return "", err
+	}
+
+	// Classify the page, so captchas, error pages and layout changes are not parsed as empty data
+	if pageType := classifyUpstreamPage(res.StatusCode(), doc); pageType != upstreamPageContent {
+		log.Printf("[warning] TibiaDataFetcher (URL: %s): tibia.com answered with a page of type %s", res.Request.URL, pageType)
+
+		if pageType == upstreamPageThrottled {
+			return "", f.throttled()
+		}
+		return "", pageType.Err()
+	}
+
+	// Find of this to get div with class BoxContent
+	data, err := doc.Find(".Border_2 .Border_3").Html()
+	if err != nil {
+		return "", err
+	}
+
+	// Return of extracted html to functions..
+	return data, nil
+}
+
+// throttled func - returns the error for a request throttled by tibia.com
+// with a limiter the following requests are paused
+func (f *tibiaDataFetcher) throttled() error {
+	if f.limiter != nil {
+		return tibiaDataRetryAfterError{validation.ErrorUpstreamThrottled, f.limiter.Throttled()}
+	}
+
+	return validation.ErrorUpstreamThrottled
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcherFunc(t *testing.T) {
+	assert := assert.New(t)
+
+	fetcher := FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+		return request.URL, nil
+	})
+
+	data, err := fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/"})
+	assert.Nil(err)
+	assert.Equal("https://www.tibia.com/", data)
+}
+
+func TestTibiaDataFetcher(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("subtopic") {
+		case "maintenance":
+			http.Redirect(w, r, "https://maintenance.tibia.com/", http.StatusFound)
+		case "throttled":
+			w.WriteHeader(http.StatusForbidden)
+		case "captcha":
+			_, _ = w.Write([]byte(`<html><body><form id="captcha"></form></body></html>`))
+		case "missing":
+			http.NotFound(w, r)
+		default:
+			_, _ = w.Write([]byte(`<html><body><div class="Border_2"><div class="Border_3"><p>content</p></div></div></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	fetcher := newTibiaDataFetcher(server.URL+"/", 2)
+
+	data, err := fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"})
+	assert.Nil(err)
+	assert.Equal("<p>content</p>", data)
+
+	// the shared client is reused between requests
+	data, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{Method: http.MethodPost, URL: "https://www.tibia.com/news/?subtopic=newsarchive", FormData: map[string]string{"filter_news": "news"}})
+	assert.Nil(err)
+	assert.Equal("<p>content</p>", data)
+
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=maintenance"})
+	assert.Equal(validation.ErrorMaintenanceMode, err)
+
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=throttled"})
+	assert.Equal(validation.ErrorUpstreamThrottled, err)
+
+	// pages without content are classified
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=captcha"})
+	assert.Equal(validation.ErrorUpstreamThrottled, err)
+
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=missing"})
+	assert.Equal(validation.ErrorUpstreamPageNotFound, err)
+
+	// with a limiter a 403 pauses the following requests
+	fetcher.limiter = newUpstreamLimiter(10, 10, 0)
+
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=throttled"})
+	var retryAfterErr tibiaDataRetryAfterError
+	assert.True(errors.As(err, &retryAfterErr))
+	assert.Equal(upstreamLimiterBackoff, retryAfterErr.retryAfter)
+
+	// the following requests are given up before they are sent to tibia.com
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"})
+	assert.True(errors.Is(err, validation.ErrorUpstreamThrottled))
+	var queueErr tibiaDataQueueError
+	assert.True(errors.As(err, &queueErr))
+}
+
+func TestTibiaDataFetcherCharset(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("subtopic") == "utf8" {
+			w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		} else {
+			w.Header().Set("Content-Type", "text/html")
+		}
+		_, _ = w.Write([]byte(`<html><body><div class="Border_2"><div class="Border_3"><p>content</p></div></div></body></html>`))
+	}))
+	defer server.Close()
+
+	fetcher := newTibiaDataFetcher(server.URL+"/", 2)
+	other := newTibiaDataFetcher(server.URL+"/", 2)
+
+	_, err := fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=utf8"})
+	assert.Nil(err)
+
+	// the charset of the response is only kept by the fetcher receiving it
+	name, _ := fetcher.charset.Get()
+	assert.Equal("utf-8", name)
+	name, _ = other.charset.Get()
+	assert.Equal("iso-8859-1", name)
+
+	escaped, err := fetcher.charset.QueryEscape("Torbjörn")
+	assert.Nil(err)
+	assert.Equal("Torbj%C3%B6rn", escaped)
+
+	// pages without a charset are latin-1
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"})
+	assert.Nil(err)
+	name, _ = fetcher.charset.Get()
+	assert.Equal("iso-8859-1", name)
+}
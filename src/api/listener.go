@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloadInterval is how often the certificate files are checked for changes
+const certReloadInterval = 10 * time.Second
+
+// certReloader serves the TLS certificate of a cert and key file
+// the files are loaded again when they change, so renewed certificates are used without restart
+type certReloader struct {
+	certFile, keyFile string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time // the latest modification time of the files
+	lastCheck time.Time
+
+	now func() time.Time
+}
+
+// newCertReloader func - creates a certReloader and loads the certificate
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		now:      time.Now,
+	}
+
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// filesModTime func - returns the latest modification time of the cert and key file
+func (r *certReloader) filesModTime() (time.Time, error) {
+	var modTime time.Time
+
+	for _, fileName := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(fileName)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+
+	return modTime, nil
+}
+
+// load func - loads the certificate of the files, the lock must be held (or r not yet shared)
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	return nil
+}
+
+// GetCertificate func - returns the current certificate, it is used as tls.Config.GetCertificate
+// if loading a changed certificate fails, the previous one is kept
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); now.Sub(r.lastCheck) >= certReloadInterval {
+		r.lastCheck = now
+
+		modTime, err := r.filesModTime()
+		switch {
+		case err != nil:
+			log.Printf("[warning] TibiaData API tls: keeping the current certificate: %s", err)
+		case modTime.After(r.modTime):
+			if err := r.load(modTime); err != nil {
+				log.Printf("[warning] TibiaData API tls: keeping the current certificate: %s", err)
+			} else {
+				log.Printf("[info] TibiaData API tls: reloaded certificate %s", r.certFile)
+			}
+		}
+	}
+
+	return r.cert, nil
+}
+
+// newListener func - returns the listener of the server
+// it listens on the unix socket if one is set and on the address otherwise, TLS is used if a certificate is set
+func newListener(config ServerConfig) (net.Listener, error) {
+	var (
+		listener net.Listener
+		err      error
+	)
+
+	if config.UnixSocket != "" {
+		// a socket left behind by a previous run would make listening fail
+		if err := os.Remove(config.UnixSocket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		listener, err = net.Listen("unix", config.UnixSocket)
+	} else {
+		listener, err = net.Listen("tcp", config.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if config.TLSCertFile == "" {
+		return listener, nil
+	}
+
+	reloader, err := newCertReloader(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("tls: %w", err)
+	}
+
+	return tls.NewListener(listener, &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}), nil
+}
+
+// newHTTPServer func - creates the http.Server of handler with the timeouts of config
+func newHTTPServer(handler http.Handler, config ServerConfig) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(config.ReadHeaderTimeoutSeconds) * time.Second,
+		ReadTimeout:       time.Duration(config.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(config.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(config.IdleTimeoutSeconds) * time.Second,
+	}
+}
+
+// serveUntilDone func - serves on listener until ctx is done and shuts the server down gracefully afterwards
+// the server is reported as not ready with setReady first and waits shutdownDelay, so load balancers stop sending requests
+// in-flight requests get shutdownTimeout to finish
+func serveUntilDone(ctx context.Context, server *http.Server, listener net.Listener, setReady func(ready bool), shutdownDelay, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("[info] TibiaData API received shutdown input")
+	if setReady != nil {
+		setReady(false)
+	}
+
+	if shutdownDelay > 0 {
+		log.Printf("[info] TibiaData API waiting %s before shutting down", shutdownDelay)
+		time.Sleep(shutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestCertificate func - writes a self-signed certificate for commonName to certFile and keyFile
+func writeTestCertificate(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fileName := range []string{certFile, keyFile} {
+		if err := os.Chtimes(fileName, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// certificateName func - returns the common name of the certificate served by r
+func certificateName(t *testing.T, r *certReloader) string {
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	modTime := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	writeTestCertificate(t, certFile, keyFile, "first.example.org", modTime)
+
+	r, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	assert.Equal("first.example.org", certificateName(t, r))
+
+	// a renewed certificate is used after the reload interval
+	writeTestCertificate(t, certFile, keyFile, "second.example.org", modTime.Add(time.Hour))
+	now = now.Add(time.Second)
+	assert.Equal("first.example.org", certificateName(t, r))
+
+	now = now.Add(certReloadInterval)
+	assert.Equal("second.example.org", certificateName(t, r))
+
+	// an invalid certificate keeps the previous one
+	if err := os.WriteFile(certFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(certFile, modTime.Add(2*time.Hour), modTime.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(certReloadInterval)
+	assert.Equal("second.example.org", certificateName(t, r))
+
+	_, err = newCertReloader(certFile, keyFile)
+	assert.NotNil(err)
+}
+
+func TestListener(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeTestCertificate(t, certFile, keyFile, "localhost", time.Now())
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "pong")
+	})
+
+	// TLS on a TCP address
+	listener, err := newListener(ServerConfig{Addr: "127.0.0.1:0", TLSCertFile: certFile, TLSKeyFile: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newHTTPServer(handler, ServerConfig{ReadTimeoutSeconds: 5})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	response, err := client.Get("https://" + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	assert.Equal("pong", string(body))
+	assert.Equal("localhost", response.TLS.PeerCertificates[0].Subject.CommonName)
+
+	// unix socket, a stale socket file is replaced
+	socket := filepath.Join(dir, "api.sock")
+	if err := os.WriteFile(socket, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err = newListener(ServerConfig{UnixSocket: socket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = newHTTPServer(handler, ServerConfig{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	response, err = client.Get("http://unix/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(response.Body)
+	response.Body.Close()
+	assert.Equal("pong", string(body))
+
+	_, err = newListener(ServerConfig{Addr: "127.0.0.1:0", TLSCertFile: filepath.Join(dir, "missing.crt"), TLSKeyFile: keyFile})
+	assert.ErrorContains(err, "tls:")
+}
+
+func TestServeUntilDone(t *testing.T) {
+	assert := assert.New(t)
+
+	var ready atomic.Bool
+	ready.Store(true)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = io.WriteString(w, "done")
+	})
+
+	listener, err := newListener(ServerConfig{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newHTTPServer(handler, ServerConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serveUntilDone(ctx, server, listener, ready.Store, 0, 5*time.Second)
+	}()
+
+	// a request in flight during the shutdown is finished
+	responded := make(chan string, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responded <- err.Error()
+			return
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		responded <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Eventually(func() bool { return !ready.Load() }, time.Second, 10*time.Millisecond)
+	close(release)
+
+	assert.Equal("done", <-responded)
+	assert.Nil(<-served)
+
+	// new connections are refused afterwards
+	_, err = http.Get("http://" + listener.Addr().String())
+	assert.NotNil(err)
+}
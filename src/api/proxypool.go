@@ -0,0 +1,261 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// the selection strategies of the proxyPool
+const (
+	proxySelectionRoundRobin   = "round-robin"
+	proxySelectionLeastLatency = "least-latency"
+)
+
+// ProxyStatus stores the health of an upstream proxy
+type ProxyStatus struct {
+	URL          string `json:"url"`                     // The base URL of the proxy.
+	Healthy      bool   `json:"healthy"`                 // Whether the proxy is used for requests.
+	EjectedUntil string `json:"ejected_until,omitempty"` // Until when the proxy is not used.
+	Failures     int    `json:"failures"`                // The number of consecutive failed requests or throttles.
+	Latency      int    `json:"latency"`                 // The average latency of the proxy in milliseconds.
+	Requests     uint64 `json:"requests"`                // The number of requests sent through the proxy.
+	Errors       uint64 `json:"errors"`                  // The number of failed requests or throttles.
+}
+
+// upstreamProxy is a proxy in front of tibia.com, e.g. on another egress IP
+type upstreamProxy struct {
+	baseURL     string // replaces https://www.tibia.com/ in request URLs
+	headerName  string // the auth header sent to the proxy (optional)
+	headerValue string
+
+	// the fields below are guarded by the lock of the proxyPool
+	failures     int
+	ejectedUntil time.Time
+	latency      time.Duration // exponentially weighted moving average
+	requests     uint64
+	errors       uint64
+}
+
+// proxyPool distributes requests over upstream proxies and ejects unhealthy ones
+type proxyPool struct {
+	mu          sync.Mutex
+	proxies     []*upstreamProxy
+	selection   string        // proxySelectionRoundRobin or proxySelectionLeastLatency
+	maxFailures int           // consecutive failures ejecting a proxy
+	ejectFor    time.Duration // how long a proxy stays ejected
+	next        int
+
+	now func() time.Time
+}
+
+// parseUpstreamProxies func - parses a comma separated list of proxies
+// every proxy is [protocol://]host[/path][|Header-Name: value], the protocol defaults to https
+func parseUpstreamProxies(value string) ([]*upstreamProxy, error) {
+	var proxies []*upstreamProxy
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		address, header, hasHeader := strings.Cut(entry, "|")
+
+		protocol, host, hasProtocol := strings.Cut(address, "://")
+		if !hasProtocol {
+			protocol, host = "https", address
+		}
+		if protocol != "http" && protocol != "https" {
+			return nil, errors.New("unsupported proxy protocol " + protocol)
+		}
+		if host == "" {
+			return nil, errors.New("missing host of proxy " + entry)
+		}
+
+		proxy := &upstreamProxy{
+			baseURL: protocol + "://" + strings.TrimSuffix(host, "/") + "/",
+		}
+
+		if hasHeader {
+			name, value, found := strings.Cut(header, ":")
+			if !found || strings.TrimSpace(name) == "" {
+				return nil, errors.New("malformed header of proxy " + address)
+			}
+			proxy.headerName = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			proxy.headerValue = strings.TrimSpace(value)
+		}
+
+		proxies = append(proxies, proxy)
+	}
+
+	if len(proxies) == 0 {
+		return nil, errors.New("no proxies configured")
+	}
+
+	return proxies, nil
+}
+
+// newProxyPool func - creates a proxyPool of proxies
+func newProxyPool(proxies []*upstreamProxy, selection string, maxFailures int, ejectFor time.Duration) *proxyPool {
+	if selection != proxySelectionLeastLatency {
+		selection = proxySelectionRoundRobin
+	}
+
+	return &proxyPool{
+		proxies:     proxies,
+		selection:   selection,
+		maxFailures: maxFailures,
+		ejectFor:    ejectFor,
+		now:         time.Now,
+	}
+}
+
+// Next func - returns the proxy to use for the next request
+// if all proxies are ejected, the one ejected the longest time ago is used
+func (p *proxyPool) Next() *upstreamProxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+
+	var selected *upstreamProxy
+	for i := range p.proxies {
+		// round-robin starts after the previously selected proxy
+		proxy := p.proxies[(p.next+i)%len(p.proxies)]
+		if now.Before(proxy.ejectedUntil) {
+			continue
+		}
+
+		if selected == nil {
+			selected = proxy
+			if p.selection == proxySelectionRoundRobin {
+				break
+			}
+		} else if proxy.latency < selected.latency {
+			selected = proxy
+		}
+	}
+
+	if selected == nil {
+		for _, proxy := range p.proxies {
+			if selected == nil || proxy.ejectedUntil.Before(selected.ejectedUntil) {
+				selected = proxy
+			}
+		}
+	}
+
+	for i, proxy := range p.proxies {
+		if proxy == selected {
+			p.next = i + 1
+		}
+	}
+	selected.requests++
+
+	return selected
+}
+
+// Record func - updates the health of proxy with the outcome of a request
+// failed is true for request errors, server errors and throttles of tibia.com
+func (p *proxyPool) Record(proxy *upstreamProxy, latency time.Duration, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !failed {
+		proxy.failures = 0
+		if proxy.latency == 0 {
+			proxy.latency = latency
+		} else {
+			proxy.latency = (4*proxy.latency + latency) / 5
+		}
+		return
+	}
+
+	proxy.failures++
+	proxy.errors++
+
+	if p.maxFailures > 0 && proxy.failures >= p.maxFailures {
+		proxy.ejectedUntil = p.now().Add(p.ejectFor)
+		proxy.failures = 0
+		log.Printf("[warning] TibiaData API proxy pool: ejecting %s for %s after %d failures", proxy.baseURL, p.ejectFor, p.maxFailures)
+	}
+}
+
+// Status func - returns the health of all proxies
+func (p *proxyPool) Status() []ProxyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+
+	status := make([]ProxyStatus, 0, len(p.proxies))
+	for _, proxy := range p.proxies {
+		proxyStatus := ProxyStatus{
+			URL:      proxy.baseURL,
+			Healthy:  !now.Before(proxy.ejectedUntil),
+			Failures: proxy.failures,
+			Latency:  int(proxy.latency.Milliseconds()),
+			Requests: proxy.requests,
+			Errors:   proxy.errors,
+		}
+		if !proxyStatus.Healthy {
+			proxyStatus.EjectedUntil = proxy.ejectedUntil.UTC().Format(time.RFC3339)
+		}
+		status = append(status, proxyStatus)
+	}
+
+	return status
+}
+
+// checkHealth func - probes ejected proxies and readmits the ones responding
+func (p *proxyPool) checkHealth(client *resty.Client) {
+	p.mu.Lock()
+	now := p.now()
+	var ejected []*upstreamProxy
+	for _, proxy := range p.proxies {
+		if now.Before(proxy.ejectedUntil) {
+			ejected = append(ejected, proxy)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, proxy := range ejected {
+		request := client.R()
+		if proxy.headerName != "" {
+			request.SetHeader(proxy.headerName, proxy.headerValue)
+		}
+
+		// redirects are fine (the client does not follow them), only request errors, server errors and throttles are not
+		res, _ := request.Get(proxy.baseURL)
+		if res == nil || res.StatusCode() == 0 || res.StatusCode() >= http.StatusInternalServerError || res.StatusCode() == http.StatusForbidden {
+			continue
+		}
+
+		p.mu.Lock()
+		proxy.ejectedUntil = time.Time{}
+		p.mu.Unlock()
+
+		log.Printf("[info] TibiaData API proxy pool: %s passed the health check and is used again", proxy.baseURL)
+	}
+}
+
+// runHealthChecks func - probes ejected proxies every interval
+func (p *proxyPool) runHealthChecks(ctx context.Context, client *resty.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.checkHealth(client)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
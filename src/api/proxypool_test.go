@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUpstreamProxies(t *testing.T) {
+	assert := assert.New(t)
+
+	proxies, err := parseUpstreamProxies("proxy1.example.com, http://proxy2.example.com/tibia/|authorization: Bearer secret")
+	assert.Nil(err)
+	assert.Len(proxies, 2)
+
+	assert.Equal("https://proxy1.example.com/", proxies[0].baseURL)
+	assert.Empty(proxies[0].headerName)
+
+	assert.Equal("http://proxy2.example.com/tibia/", proxies[1].baseURL)
+	assert.Equal("Authorization", proxies[1].headerName)
+	assert.Equal("Bearer secret", proxies[1].headerValue)
+
+	_, err = parseUpstreamProxies("ftp://proxy.example.com")
+	assert.NotNil(err)
+
+	_, err = parseUpstreamProxies("proxy.example.com|no-header")
+	assert.NotNil(err)
+
+	_, err = parseUpstreamProxies(" , ")
+	assert.NotNil(err)
+}
+
+func TestProxyPoolRoundRobin(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	proxies, _ := parseUpstreamProxies("a.example.com,b.example.com,c.example.com")
+	pool := newProxyPool(proxies, proxySelectionRoundRobin, 2, time.Minute)
+	pool.now = func() time.Time { return now }
+
+	assert.Equal(proxies[0], pool.Next())
+	assert.Equal(proxies[1], pool.Next())
+	assert.Equal(proxies[2], pool.Next())
+	assert.Equal(proxies[0], pool.Next())
+
+	// repeated failures eject a proxy
+	pool.Record(proxies[1], time.Second, true)
+	assert.Equal(proxies[1], pool.Next())
+	pool.Record(proxies[1], time.Second, true)
+	assert.Equal(proxies[2], pool.Next())
+	assert.Equal(proxies[0], pool.Next())
+	assert.Equal(proxies[2], pool.Next())
+
+	status := pool.Status()
+	assert.False(status[1].Healthy)
+	assert.Equal("2023-06-01T10:01:00Z", status[1].EjectedUntil)
+	assert.EqualValues(2, status[1].Errors)
+	assert.EqualValues(2, status[1].Requests)
+
+	// ejected proxies are used again afterwards
+	now = now.Add(2 * time.Minute)
+	assert.Equal(proxies[0], pool.Next())
+	assert.Equal(proxies[1], pool.Next())
+}
+
+func TestProxyPoolLeastLatency(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	proxies, _ := parseUpstreamProxies("a.example.com,b.example.com")
+	pool := newProxyPool(proxies, proxySelectionLeastLatency, 1, time.Minute)
+	pool.now = func() time.Time { return now }
+
+	pool.Record(proxies[0], 300*time.Millisecond, false)
+	pool.Record(proxies[1], 100*time.Millisecond, false)
+
+	assert.Equal(proxies[1], pool.Next())
+	assert.Equal(proxies[1], pool.Next())
+
+	// the latency is averaged over requests
+	pool.Record(proxies[1], 1100*time.Millisecond, false)
+	assert.Equal(300, pool.Status()[1].Latency)
+	assert.Equal(proxies[0], pool.Next())
+
+	// if all proxies are ejected, the one ejected first is used
+	pool.Record(proxies[0], 0, true)
+	now = now.Add(time.Second)
+	pool.Record(proxies[1], 0, true)
+	assert.Equal(proxies[0], pool.Next())
+}
+
+func TestProxyPoolFetcher(t *testing.T) {
+	assert := assert.New(t)
+
+	var throttled bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Proxy-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if throttled {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="Border_2"><div class="Border_3">` + strings.TrimPrefix(r.URL.Path, "/") + `</div></div>`))
+	}))
+	defer server.Close()
+
+	proxies, err := parseUpstreamProxies(server.URL + "/one|X-Proxy-Key: secret," + server.URL + "/two|X-Proxy-Key: secret")
+	assert.Nil(err)
+
+	fetcher := newTibiaDataFetcher("", 2)
+	fetcher.proxies = newProxyPool(proxies, proxySelectionRoundRobin, 1, time.Minute)
+
+	data, err := fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/"})
+	assert.Nil(err)
+	assert.Equal("one/community/", data)
+
+	data, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/"})
+	assert.Nil(err)
+	assert.Equal("two/community/", data)
+
+	// a throttled proxy is ejected
+	throttled = true
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/"})
+	assert.NotNil(err)
+	assert.False(fetcher.proxies.Status()[0].Healthy)
+
+	// and readmitted once it passes the health check
+	throttled = false
+	fetcher.proxies.checkHealth(fetcher.client)
+	assert.True(fetcher.proxies.Status()[0].Healthy)
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+)
+
+// tibiaDataRetryAfterError wraps errors after which the client should retry later
+// they are sent as 503 with a Retry-After header
+type tibiaDataRetryAfterError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e tibiaDataRetryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e tibiaDataRetryAfterError) Unwrap() error {
+	return e.err
+}
+
+const (
+	// upstreamLimiterBackoff is how long no requests are sent after a 403 of tibia.com
+	// it doubles on every consecutive 403 up to upstreamLimiterMaxBackoff
+	upstreamLimiterBackoff    = 5 * time.Second
+	upstreamLimiterMaxBackoff = 5 * time.Minute
+
+	// upstreamLimiterQuietPeriod is how long tibia.com must not throttle us
+	// before the rate is increased again by a tenth of the configured rate
+	upstreamLimiterQuietPeriod = 1 * time.Minute
+)
+
+// upstreamLimiter is a token bucket shared by all requests to tibia.com
+// the rate is halved when tibia.com throttles us and slowly recovers afterwards
+type upstreamLimiter struct {
+	mu      sync.Mutex
+	rate    float64       // current tokens per second
+	minRate float64       // the rate is never lowered below minRate
+	maxRate float64       // the configured rate
+	burst   float64       // maximum number of tokens
+	maxWait time.Duration // requests needing to wait longer fail fast
+
+	tokens float64   // available tokens (negative when requests are waiting)
+	last   time.Time // tokens accrue from last (in the future while backing off)
+
+	backoff        time.Duration // the backoff of the last 403
+	throttledUntil time.Time     // end of the current backoff
+	recoveredAt    time.Time     // the last time the rate was changed
+
+	now func() time.Time
+}
+
+// newUpstreamLimiter func - creates an upstreamLimiter allowing rate requests per second
+func newUpstreamLimiter(rate float64, burst int, maxWait time.Duration) *upstreamLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := time.Now()
+
+	return &upstreamLimiter{
+		rate:        rate,
+		minRate:     rate / 10,
+		maxRate:     rate,
+		burst:       float64(burst),
+		maxWait:     maxWait,
+		tokens:      float64(burst),
+		last:        now,
+		recoveredAt: now,
+		now:         time.Now,
+	}
+}
+
+// Wait func - blocks until a request may be sent to tibia.com
+// returns validation.ErrorUpstreamThrottled if that would take longer than maxWait or the deadline of ctx
+func (l *upstreamLimiter) Wait(ctx context.Context) error {
+	maxWait := l.maxWait
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < maxWait {
+		maxWait = time.Until(deadline)
+	}
+
+	reservedAt := l.now()
+	wait, err := l.reserve(maxWait)
+	if err != nil {
+		return err
+	}
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			// the request is not sent, so its token goes back to the following requests
+			l.refund(reservedAt)
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// refund func - gives back a token reserved at reservedAt that was not used
+// tokens reserved before the last 403 are not given back, the backoff dropped them already
+func (l *upstreamLimiter) refund(reservedAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.throttledUntil.Add(-l.backoff).After(reservedAt) {
+		return
+	}
+
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// reserve func - takes a token and returns how long to wait before using it
+func (l *upstreamLimiter) reserve(maxWait time.Duration) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.recover(now)
+	l.advance(now)
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, nil
+	}
+
+	wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	if now.Before(l.last) {
+		wait += l.last.Sub(now)
+	}
+
+	if wait > maxWait {
+		return 0, tibiaDataRetryAfterError{validation.ErrorUpstreamThrottled, wait}
+	}
+
+	// the token is taken in advance, so the following requests wait behind this one
+	l.tokens--
+
+	return wait, nil
+}
+
+// Throttled func - registers a 403 of tibia.com and returns how long no requests will be sent
+func (l *upstreamLimiter) Throttled() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	// requests sent before the backoff started are not counted again
+	if now.Before(l.throttledUntil) {
+		return l.throttledUntil.Sub(now)
+	}
+
+	// consecutive 403s double the backoff
+	if l.backoff > 0 && now.Sub(l.throttledUntil) < upstreamLimiterQuietPeriod {
+		l.backoff = time.Duration(math.Min(float64(2*l.backoff), float64(upstreamLimiterMaxBackoff)))
+	} else {
+		l.backoff = upstreamLimiterBackoff
+	}
+
+	l.rate = math.Max(l.minRate, l.rate/2)
+	l.tokens = 0
+	l.last = now.Add(l.backoff)
+	l.throttledUntil = l.last
+	l.recoveredAt = l.last
+
+	log.Printf("[warning] TibiaData API upstream limiter: throttled by tibia.com, pausing for %s and lowering rate to %.2f/s", l.backoff, l.rate)
+
+	return l.backoff
+}
+
+// advance func - adds the tokens accrued since last, the lock must be held
+func (l *upstreamLimiter) advance(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// recover func - increases the rate after every quiet period, the lock must be held
+func (l *upstreamLimiter) recover(now time.Time) {
+	if l.rate >= l.maxRate || now.Sub(l.recoveredAt) < upstreamLimiterQuietPeriod {
+		return
+	}
+
+	// tokens accrued at the old rate are added before changing it
+	l.advance(now)
+
+	periods := int(now.Sub(l.recoveredAt) / upstreamLimiterQuietPeriod)
+	l.rate = math.Min(l.maxRate, l.rate+float64(periods)*l.maxRate/10)
+	l.recoveredAt = l.recoveredAt.Add(time.Duration(periods) * upstreamLimiterQuietPeriod)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	limiter := newUpstreamLimiter(2, 2, time.Second)
+	limiter.now = func() time.Time { return now }
+	limiter.last = now
+	limiter.recoveredAt = now
+
+	// the burst is available right away
+	for i := 0; i < 2; i++ {
+		wait, err := limiter.reserve(limiter.maxWait)
+		assert.Nil(err)
+		assert.Equal(time.Duration(0), wait)
+	}
+
+	// the next requests wait for new tokens
+	wait, err := limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(500*time.Millisecond, wait)
+
+	wait, err = limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(time.Second, wait)
+
+	// and fail fast once they would wait longer than maxWait
+	_, err = limiter.reserve(limiter.maxWait)
+	var retryAfterErr tibiaDataRetryAfterError
+	assert.True(errors.As(err, &retryAfterErr))
+	assert.Equal(validation.ErrorUpstreamThrottled, retryAfterErr.err)
+	assert.Equal(1500*time.Millisecond, retryAfterErr.retryAfter)
+
+	// tokens accrue over time up to the burst
+	now = now.Add(time.Minute)
+	wait, err = limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(time.Duration(0), wait)
+}
+
+func TestUpstreamLimiterCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	limiter := newUpstreamLimiter(2, 1, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limiter.last = now
+	limiter.recoveredAt = now
+
+	wait, err := limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(time.Duration(0), wait)
+
+	// a request cancelled while waiting gives its token back
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(limiter.Wait(ctx), context.Canceled)
+	assert.Equal(0.0, limiter.tokens)
+
+	wait, err = limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(500*time.Millisecond, wait)
+
+	// tokens reserved before a 403 are not given back, the backoff dropped them already
+	reservedAt := now
+	now = now.Add(time.Second)
+	limiter.Throttled()
+	limiter.refund(reservedAt)
+	assert.Equal(0.0, limiter.tokens)
+
+	// the burst is never exceeded
+	limiter.refund(now)
+	limiter.refund(now)
+	assert.Equal(1.0, limiter.tokens)
+}
+
+func TestUpstreamLimiterBackoff(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	limiter := newUpstreamLimiter(10, 10, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limiter.last = now
+	limiter.recoveredAt = now
+
+	// a 403 halves the rate and pauses all requests
+	assert.Equal(upstreamLimiterBackoff, limiter.Throttled())
+	assert.Equal(5.0, limiter.rate)
+
+	wait, err := limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(upstreamLimiterBackoff+200*time.Millisecond, wait)
+
+	// 403s of requests sent before the backoff are not counted again
+	now = now.Add(time.Second)
+	assert.Equal(upstreamLimiterBackoff-time.Second, limiter.Throttled())
+	assert.Equal(5.0, limiter.rate)
+
+	// consecutive 403s double the backoff
+	now = now.Add(upstreamLimiterBackoff)
+	assert.Equal(2*upstreamLimiterBackoff, limiter.Throttled())
+	assert.Equal(2.5, limiter.rate)
+
+	// the rate recovers slowly after a quiet period
+	now = now.Add(2*upstreamLimiterBackoff + upstreamLimiterQuietPeriod)
+	_, err = limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(3.5, limiter.rate)
+
+	now = now.Add(10 * upstreamLimiterQuietPeriod)
+	_, err = limiter.reserve(limiter.maxWait)
+	assert.Nil(err)
+	assert.Equal(10.0, limiter.rate)
+
+	// after a quiet period the backoff starts over
+	assert.Equal(upstreamLimiterBackoff, limiter.Throttled())
+}
+
+func TestRetryAfterErrorHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	TibiaDataErrorHandler(c, tibiaDataRetryAfterError{validation.ErrorUpstreamThrottled, 1500 * time.Millisecond}, http.StatusBadGateway)
+
+	assert.Equal(http.StatusServiceUnavailable, w.Code)
+	assert.Equal("2", w.Header().Get("Retry-After"))
+	assert.Contains(w.Body.String(), `"error":20006`)
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisCache is a ResponseCache storing entries in a server speaking the redis protocol
+// so the cache can be shared by multiple replicas of the API
+type redisCache struct {
+	client     redis.UniversalClient
+	namespace  string        // prefix of all keys (based on API version and release)
+	timeout    time.Duration // maximum duration of a command
+	maxEntries int           // maximum number of entries (0 for no limit), the oldest entries are removed first
+	index      string        // key of the sorted set of the entries by age (used with maxEntries)
+
+	// when the backend is unreachable it is skipped until unavailableUntil
+	// so requests are served without caching instead of waiting on it
+	mu               sync.Mutex
+	unavailableUntil time.Time
+
+	hits, misses, errors uint64
+}
+
+// redisCacheBackoff is how long the backend is skipped after an error
+const redisCacheBackoff = 10 * time.Second
+
+// newRedisCache func - creates a redisCache connecting to addr
+func newRedisCache(addr, password string, db int, apiDetails APIDetails) *redisCache {
+	return &redisCache{
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			Password:     password,
+			DB:           db,
+			DialTimeout:  time.Second,
+			ReadTimeout:  500 * time.Millisecond,
+			WriteTimeout: 500 * time.Millisecond,
+			MaxRetries:   -1, // errors are handled by skipping the cache
+		}),
+		namespace: redisNamespace("tibiadata", apiDetails) + ":",
+		timeout:   time.Second,
+	}
+}
+
+// newRedisStaleCache func - creates a redisCache for the last successful responses using the connection of cache
+// the entries have a namespace of their own, so they are neither purged nor counted with the entries of cache
+func newRedisStaleCache(cache *redisCache, apiDetails APIDetails, maxEntries int) *redisCache {
+	return &redisCache{
+		client:     cache.client,
+		namespace:  redisNamespace("tibiadata-stale", apiDetails) + ":",
+		timeout:    cache.timeout,
+		maxEntries: maxEntries,
+		index:      redisNamespace("tibiadata-stale-index", apiDetails),
+	}
+}
+
+// redisNamespace func - returns the namespace of name for the API version and release
+func redisNamespace(name string, apiDetails APIDetails) string {
+	return name + ":v" + strconv.Itoa(apiDetails.Version) + ":" + apiDetails.Release
+}
+
+// Ping func - checks whether the backend is reachable
+func (r *redisCache) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	return r.client.Ping(ctx).Err()
+}
+
+// Get func - returns the entry of key if it exists in the backend
+func (r *redisCache) Get(key string) (CacheEntry, bool) {
+	if !r.available() {
+		atomic.AddUint64(&r.misses, 1)
+		return CacheEntry{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	data, err := r.client.Get(ctx, r.namespace+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.failed(err)
+		}
+		atomic.AddUint64(&r.misses, 1)
+		return CacheEntry{}, false
+	}
+
+	entry, err := decodeRedisCacheEntry(data)
+	if err != nil {
+		r.failed(err)
+		atomic.AddUint64(&r.misses, 1)
+		return CacheEntry{}, false
+	}
+
+	atomic.AddUint64(&r.hits, 1)
+
+	return entry, true
+}
+
+// Set func - stores the entry of key in the backend for the duration of ttl
+func (r *redisCache) Set(key string, entry CacheEntry, ttl time.Duration) {
+	if !r.available() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.client.Set(ctx, r.namespace+key, encodeRedisCacheEntry(entry), ttl).Err(); err != nil {
+		r.failed(err)
+		return
+	}
+
+	if r.maxEntries > 0 {
+		if err := r.trim(ctx, key); err != nil {
+			r.failed(err)
+		}
+	}
+}
+
+// trim func - adds key to the index and removes the oldest entries above maxEntries
+func (r *redisCache) trim(ctx context.Context, key string) error {
+	if err := r.client.ZAdd(ctx, r.index, redis.Z{Score: float64(time.Now().UnixMilli()), Member: key}).Err(); err != nil {
+		return err
+	}
+
+	count, err := r.client.ZCard(ctx, r.index).Result()
+	if err != nil || count <= int64(r.maxEntries) {
+		return err
+	}
+
+	oldest, err := r.client.ZPopMin(ctx, r.index, count-int64(r.maxEntries)).Result()
+	if err != nil || len(oldest) == 0 {
+		return err
+	}
+
+	keys := make([]string, len(oldest))
+	for i, member := range oldest {
+		keys[i] = r.namespace + member.Member.(string)
+	}
+
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// Delete func - removes the entry of key from the backend
+func (r *redisCache) Delete(key string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	deleted, err := r.client.Del(ctx, r.namespace+key).Result()
+
+	return int(deleted), err
+}
+
+// DeletePrefix func - removes the entries whose key starts with prefix from the backend
+// the keys are scanned in batches, so the backend is not blocked
+func (r *redisCache) DeletePrefix(prefix string) (int, error) {
+	var (
+		cursor  uint64
+		deleted int
+	)
+
+	match := redisGlobEscaper.Replace(r.namespace+prefix) + "*"
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		keys, next, err := r.client.Scan(ctx, cursor, match, 100).Result()
+		if err == nil && len(keys) > 0 {
+			var n int64
+			n, err = r.client.Del(ctx, keys...).Result()
+			deleted += int(n)
+		}
+		cancel()
+
+		if err != nil {
+			return deleted, err
+		}
+
+		if cursor = next; cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// redisGlobEscaper escapes the special characters of redis patterns (the keys contain urls with ?)
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// Stats func - returns the counters of the cache
+func (r *redisCache) Stats() CacheStats {
+	return CacheStats{
+		Backend: "redis",
+		Hits:    atomic.LoadUint64(&r.hits),
+		Misses:  atomic.LoadUint64(&r.misses),
+		Errors:  atomic.LoadUint64(&r.errors),
+	}
+}
+
+// available func - reports whether the backend should be used
+func (r *redisCache) available() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return time.Now().After(r.unavailableUntil)
+}
+
+// failed func - registers an error and skips the backend for a while
+func (r *redisCache) failed(err error) {
+	atomic.AddUint64(&r.errors, 1)
+
+	r.mu.Lock()
+	r.unavailableUntil = time.Now().Add(redisCacheBackoff)
+	r.mu.Unlock()
+
+	log.Printf("[warning] TibiaData API redis cache unavailable, serving without cache for %s: %s", redisCacheBackoff, err)
+}
+
+// encodeRedisCacheEntry func - serializes an entry as "<stored at unix milli>[ <expires at unix milli>]\n<data>"
+func encodeRedisCacheEntry(entry CacheEntry) []byte {
+	header := strconv.FormatInt(entry.StoredAt.UnixMilli(), 10)
+	if !entry.ExpiresAt.IsZero() {
+		header += " " + strconv.FormatInt(entry.ExpiresAt.UnixMilli(), 10)
+	}
+
+	return append([]byte(header+"\n"), entry.Data...)
+}
+
+// decodeRedisCacheEntry func - deserializes an entry created by encodeRedisCacheEntry
+func decodeRedisCacheEntry(data []byte) (CacheEntry, error) {
+	header, body, found := bytes.Cut(data, []byte("\n"))
+	if !found {
+		return CacheEntry{}, errors.New("malformed cache entry")
+	}
+
+	storedAt, expiresAt, hasExpiresAt := bytes.Cut(header, []byte(" "))
+
+	storedAtMilli, err := strconv.ParseInt(string(storedAt), 10, 64)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+
+	entry := CacheEntry{
+		Data:     body,
+		StoredAt: time.UnixMilli(storedAtMilli),
+	}
+
+	if hasExpiresAt {
+		expiresAtMilli, err := strconv.ParseInt(string(expiresAt), 10, 64)
+		if err != nil {
+			return CacheEntry{}, err
+		}
+		entry.ExpiresAt = time.UnixMilli(expiresAtMilli)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisCache(t *testing.T) {
+	assert := assert.New(t)
+
+	server := miniredis.RunT(t)
+
+	cache := newRedisCache(server.Addr(), "", 0, APIDetails{Version: 4, Release: "1.2.3"})
+	assert.Nil(cache.Ping())
+
+	_, ok := cache.Get("a")
+	assert.False(ok)
+
+	storedAt := time.UnixMilli(time.Now().UnixMilli())
+	cache.Set("a", CacheEntry{Data: []byte(`{"a":1}`), StoredAt: storedAt}, time.Minute)
+
+	// keys are namespaced by api version and release
+	assert.True(server.Exists("tibiadata:v4:1.2.3:a"))
+
+	entry, ok := cache.Get("a")
+	assert.True(ok)
+	assert.Equal([]byte(`{"a":1}`), entry.Data)
+	assert.True(storedAt.Equal(entry.StoredAt))
+
+	// other releases do not share entries
+	otherRelease := newRedisCache(server.Addr(), "", 0, APIDetails{Version: 4, Release: "1.2.4"})
+	_, ok = otherRelease.Get("a")
+	assert.False(ok)
+
+	// entries expire
+	server.FastForward(2 * time.Minute)
+	_, ok = cache.Get("a")
+	assert.False(ok)
+
+	stats := cache.Stats()
+	assert.Equal("redis", stats.Backend)
+	assert.EqualValues(1, stats.Hits)
+	assert.EqualValues(2, stats.Misses)
+	assert.EqualValues(0, stats.Errors)
+}
+
+func TestRedisCacheDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	server := miniredis.RunT(t)
+
+	cache := newRedisCache(server.Addr(), "", 0, APIDetails{Version: 4, Release: "1.2.3"})
+	otherRelease := newRedisCache(server.Addr(), "", 0, APIDetails{Version: 4, Release: "1.2.4"})
+
+	for _, key := range []string{"GET https://www.tibia.com/community/?subtopic=worlds", "GET https://www.tibia.com/community/?subtopic=worlds&world=Antica", "GET https://www.tibia.com/community/Xsubtopic=worlds"} {
+		cache.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+		otherRelease.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+	}
+
+	deleted, err := cache.Delete("GET https://www.tibia.com/community/?subtopic=worlds&world=Antica")
+	assert.Nil(err)
+	assert.Equal(1, deleted)
+
+	// the ? of the prefix is no wildcard
+	deleted, err = cache.DeletePrefix("GET https://www.tibia.com/community/?subtopic=")
+	assert.Nil(err)
+	assert.Equal(1, deleted)
+
+	// only the entries of the release are removed
+	deleted, err = cache.DeletePrefix("")
+	assert.Nil(err)
+	assert.Equal(1, deleted)
+	assert.Len(server.Keys(), 3)
+
+	server.Close()
+	_, err = cache.DeletePrefix("")
+	assert.NotNil(err)
+}
+
+func TestRedisStaleCache(t *testing.T) {
+	assert := assert.New(t)
+
+	server := miniredis.RunT(t)
+
+	apiDetails := APIDetails{Version: 4, Release: "1.2.3"}
+	cache := newRedisCache(server.Addr(), "", 0, apiDetails)
+	stale := newRedisStaleCache(cache, apiDetails, 2)
+
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+		stale.Set(key, CacheEntry{Data: []byte("data")}, time.Minute)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// the stale entries have a namespace and a limit of their own
+	assert.True(server.Exists("tibiadata:v4:1.2.3:a"))
+	assert.False(server.Exists("tibiadata-stale:v4:1.2.3:a"))
+	assert.True(server.Exists("tibiadata-stale:v4:1.2.3:b"))
+	assert.True(server.Exists("tibiadata-stale:v4:1.2.3:c"))
+
+	_, ok := stale.Get("a")
+	assert.False(ok)
+	_, ok = stale.Get("c")
+	assert.True(ok)
+
+	// purging the cache keeps the stale entries
+	deleted, err := cache.DeletePrefix("")
+	assert.Nil(err)
+	assert.Equal(3, deleted)
+
+	deleted, err = stale.DeletePrefix("")
+	assert.Nil(err)
+	assert.Equal(2, deleted)
+}
+
+func TestRedisCacheUnreachable(t *testing.T) {
+	assert := assert.New(t)
+
+	server := miniredis.RunT(t)
+
+	cache := newRedisCache(server.Addr(), "", 0, APIDetails{Version: 4, Release: "1.2.3"})
+	cache.Set("a", CacheEntry{Data: []byte(`{"a":1}`), StoredAt: time.Now()}, time.Minute)
+
+	server.Close()
+
+	// lookups fall back to misses and the backend is skipped
+	_, ok := cache.Get("a")
+	assert.False(ok)
+	assert.False(cache.available())
+	assert.EqualValues(1, cache.Stats().Errors)
+
+	cache.Set("b", CacheEntry{Data: []byte(`{"b":1}`), StoredAt: time.Now()}, time.Minute)
+	_, ok = cache.Get("b")
+	assert.False(ok)
+	assert.EqualValues(1, cache.Stats().Errors)
+}
+
+func TestRedisCacheEntryEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	entry := CacheEntry{Data: []byte("{\n}"), StoredAt: time.UnixMilli(1685613600000)}
+
+	encoded := encodeRedisCacheEntry(entry)
+	assert.Equal("1685613600000\n{\n}", string(encoded))
+
+	decoded, err := decodeRedisCacheEntry(encoded)
+	assert.Nil(err)
+	assert.Equal(entry.Data, decoded.Data)
+	assert.True(entry.StoredAt.Equal(decoded.StoredAt))
+
+	// entries with an expiry keep it
+	entry.ExpiresAt = time.UnixMilli(1685613660000)
+
+	encoded = encodeRedisCacheEntry(entry)
+	assert.Equal("1685613600000 1685613660000\n{\n}", string(encoded))
+
+	decoded, err = decodeRedisCacheEntry(encoded)
+	assert.Nil(err)
+	assert.True(entry.ExpiresAt.Equal(decoded.ExpiresAt))
+
+	_, err = decodeRedisCacheEntry([]byte("malformed"))
+	assert.NotNil(err)
+}
@@ -0,0 +1,492 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-contrib/gzip"
+	"github.com/gin-gonic/gin"
+)
+
+// The route groups of the API, all of them are enabled by default
+const (
+	RouteGroupPing     = "ping"     // /ping
+	RouteGroupHealth   = "health"   // /health, /healthz and /readyz
+	RouteGroupDebug    = "debug"    // /debug
+	RouteGroupVersions = "versions" // /versions
+	RouteGroupV3       = "v3"       // /v3 (deprecated)
+	RouteGroupV4       = "v4"       // /v4
+	RouteGroupAdmin    = "admin"    // /admin (only available with API keys)
+)
+
+// routeGroups are all route groups
+var routeGroups = []string{RouteGroupPing, RouteGroupHealth, RouteGroupDebug, RouteGroupVersions, RouteGroupV3, RouteGroupV4, RouteGroupAdmin}
+
+// RouterOptions holds the options of NewRouter
+type RouterOptions struct {
+	// Config holds the settings of the API (nil for the defaults)
+	// the process-wide settings like the host and the edition are set by TibiaDataInitializer
+	Config *Config
+
+	// BasePath is the path the routes are mounted under, like /tibiadata (empty for /)
+	BasePath string
+
+	// RouteGroups are the enabled route groups like RouteGroupV4 (empty for all)
+	RouteGroups []string
+
+	// Middleware runs before the handlers of all routes
+	Middleware []gin.HandlerFunc
+
+	// Fetcher retrieves the pages of tibia.com (nil for the pooled fetcher of Config)
+	Fetcher Fetcher
+
+	// Validator validates the requests with the tibiamapping data, it is loaded unless it already is
+	// (nil for the validator shared with the package functions of validation)
+	Validator *validation.Validator
+}
+
+// Router is the http.Handler of the TibiaData API
+// the background jobs (proxy health checks and cache warmer) only run during Run
+// it is reported as ready on /readyz from its creation until SetReady(false)
+type Router struct {
+	engine *gin.Engine
+	server *webServer
+	jobs   []func(ctx context.Context)
+}
+
+// NewRouter func - creates the Router of the API with opts
+func NewRouter(opts RouterOptions) (*Router, error) {
+	config := opts.Config
+	if config == nil {
+		defaultConfig := defaultConfig()
+		config = &defaultConfig
+	}
+
+	basePath := strings.TrimSuffix(opts.BasePath, "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		return nil, fmt.Errorf("base path %q does not start with /", opts.BasePath)
+	}
+
+	// all route groups are enabled if none is set
+	enabled := make(map[string]bool)
+	for _, group := range routeGroups {
+		enabled[group] = len(opts.RouteGroups) == 0
+	}
+	for _, group := range opts.RouteGroups {
+		if _, ok := enabled[group]; !ok {
+			return nil, fmt.Errorf("route group %q is not one of %q", group, routeGroups)
+		}
+		enabled[group] = true
+	}
+
+	// Loading the tibiamapping data used by the validation of the requests
+	validator := opts.Validator
+	if validator == nil {
+		validator = validation.Default()
+	}
+	if err := tibiaDataInitValidator(validator); err != nil {
+		return nil, fmt.Errorf("tibiamapping: %w", err)
+	}
+
+	r := &Router{}
+
+	// Setting up the circuit breaker for tibia.com
+	s := &webServer{
+		config: config,
+		circuit: newUpstreamCircuit(
+			config.Circuit.FailureThreshold,
+			time.Duration(config.Circuit.OpenSeconds)*time.Second,
+			time.Duration(config.Circuit.MaintenanceSeconds)*time.Second,
+		),
+		validator: validator,
+	}
+	r.server = s
+
+	// Setting the duration of the server save window (tibia.com is offline from 10:00 CET/CEST)
+	s.serverSave = newServerSaveSchedule(time.Duration(config.Upstream.ServerSaveMinutes) * time.Minute)
+
+	// Setting the deadline budget of the upstream requests of a handler (including retries)
+	s.defaultDeadline = time.Duration(config.Upstream.DeadlineSeconds) * time.Second
+
+	// Setting the debug mode of the router, it can be toggled at runtime on /admin/debug
+	s.debug = new(atomic.Bool)
+	s.debug.Store(config.Debug)
+
+	// Setting up the shared upstream fetcher unless another fetcher is used
+	if opts.Fetcher != nil {
+		s.fetcher = opts.Fetcher
+	} else {
+		fetcher, err := newConfigFetcher(config)
+		if err != nil {
+			return nil, err
+		}
+		fetcher.setDebug(s.debug)
+
+		s.fetcher = fetcher
+		s.proxies = fetcher.proxies
+		s.workers = fetcher.workers
+		s.upstreamCharset = fetcher.charset
+
+		if interval := config.Proxies.HealthCheckSeconds; interval > 0 && fetcher.proxies != nil {
+			r.jobs = append(r.jobs, func(ctx context.Context) {
+				fetcher.proxies.runHealthChecks(ctx, fetcher.client, time.Duration(interval)*time.Second)
+			})
+		}
+	}
+
+	// Setting up the response cache if cache.backend (TIBIADATA_CACHE) is set
+	switch cacheBackend := config.Cache.Backend; cacheBackend {
+	case "memory":
+		s.cache = newMemoryCache(
+			config.Cache.MaxEntries,
+			int64(config.Cache.MaxSizeMB)*1024*1024,
+		)
+		log.Printf("[info] TibiaData API cache: %s", cacheBackend)
+	case "redis":
+		redisCache := newRedisCache(
+			config.Cache.RedisAddr,
+			config.Cache.RedisPassword,
+			config.Cache.RedisDB,
+			TibiaDataAPIDetails,
+		)
+		if err := redisCache.Ping(); err != nil {
+			redisCache.failed(err)
+		}
+		s.cache = redisCache
+		log.Printf("[info] TibiaData API cache: %s (%s)", cacheBackend, config.Cache.RedisAddr)
+	}
+
+	// Setting up stale-if-error if cache.stale_if_error (TIBIADATA_STALE_IF_ERROR) is true
+	// the last successful responses are also kept for the server save (upstream.server_save_minutes above 0),
+	// apart from the cache with limits of their own, in redis with the redis backend and in memory otherwise
+	if config.Cache.StaleIfError || config.Upstream.ServerSaveMinutes > 0 {
+		if redisCache, ok := s.cache.(*redisCache); ok {
+			s.stale = newRedisStaleCache(redisCache, TibiaDataAPIDetails, config.Cache.StaleMaxEntries)
+		} else {
+			s.stale = newMemoryCache(config.Cache.StaleMaxEntries, int64(config.Cache.StaleMaxSizeMB)*1024*1024)
+		}
+		s.staleMaxAge = time.Duration(config.Cache.StaleMaxAgeHours) * time.Hour
+		s.staleIfError = config.Cache.StaleIfError
+		log.Printf("[info] TibiaData API stale-if-error: %t (last successful responses kept for %s)", s.staleIfError, s.staleMaxAge)
+	}
+
+	// Setting up API keys if api_keys (TIBIADATA_API_KEYS_FILE or TIBIADATA_API_KEYS) is set
+	if len(config.APIKeys.Keys) > 0 {
+		var err error
+		s.apiKeys, err = newAPIKeyStore(config.APIKeys.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("api keys: %w", err)
+		}
+		s.apiKeys.basePath = basePath
+		log.Printf("[info] TibiaData API keys: %d keys", len(config.APIKeys.Keys))
+	}
+
+	// Setting up the rate limit per client IP (requests per minute, 0 disables it)
+	// expensive routes sending several requests to tibia.com have a lower limit of their own
+	if perMinute := config.RateLimit.PerMinute; perMinute > 0 {
+		var expensive *clientLimiter
+		if expensivePerMinute := config.RateLimit.ExpensivePerMinute; expensivePerMinute > 0 {
+			expensive = newClientLimiter(expensivePerMinute, config.RateLimit.ExpensiveBurst)
+		}
+
+		expensiveRoutes := make([]string, len(config.RateLimit.ExpensiveRoutes))
+		for i, route := range config.RateLimit.ExpensiveRoutes {
+			expensiveRoutes[i] = basePath + strings.TrimSpace(route)
+		}
+
+		s.limiters = newClientLimiters(
+			newClientLimiter(perMinute, config.RateLimit.Burst),
+			expensive,
+			strings.Join(expensiveRoutes, ","),
+		)
+		log.Printf("[info] TibiaData API rate limit: %d/min per client", perMinute)
+	}
+
+	// Starting an Engine instance
+	router := gin.Default()
+	r.engine = router
+
+	// Gin middleware to write the responses with the settings of the router
+	router.Use(s.withWebServer)
+
+	// Gin middleware to enable GZIP support
+	router.Use(gzip.Gzip(gzip.DefaultCompression))
+
+	// Gin middleware to enable CORS if cors.allowed_origins (TIBIADATA_CORS_ALLOWED_ORIGINS) is set
+	if len(config.CORS.AllowedOrigins) > 0 {
+		cors := newCORSPolicy(
+			strings.Join(config.CORS.AllowedOrigins, ","),
+			strings.Join(config.CORS.AllowedMethods, ","),
+			strings.Join(config.CORS.AllowedHeaders, ","),
+			strings.Join(config.CORS.ExposedHeaders, ","),
+			time.Duration(config.CORS.MaxAgeSeconds)*time.Second,
+		)
+		cors.prefix = basePath + cors.prefix
+		router.Use(cors.Middleware())
+		log.Printf("[info] TibiaData API cors: %s", cors.origins)
+	}
+
+	// Set 404 not found page
+	router.NoRoute(func(c *gin.Context) {
+		TibiaDataErrorHandler(
+			c,
+			ErrorNotFound,
+			http.StatusNotFound,
+		)
+	})
+
+	// Set proxy feature of gin
+	if len(config.TrustedProxies) > 0 {
+		_ = router.SetTrustedProxies(config.TrustedProxies)
+		log.Printf("[info] TibiaData API gin-trusted-proxies: %s", config.TrustedProxies)
+	} else {
+		_ = router.SetTrustedProxies(nil)
+	}
+
+	// All routes are mounted under the base path
+	base := router.Group(basePath, opts.Middleware...)
+
+	// Set the ping endpoint
+	if enabled[RouteGroupPing] {
+		base.GET("/ping", func(c *gin.Context) {
+			data := Information{
+				APIDetails: TibiaDataAPIDetails,
+				Timestamp:  TibiaDataDatetime(""),
+				Status: Status{
+					HTTPCode: http.StatusOK,
+					Message:  "pong",
+				},
+			}
+
+			var output OutInformation
+			output.Information = data
+
+			c.JSON(http.StatusOK, output)
+		})
+	}
+
+	// health endpoints for kubernetes
+	if enabled[RouteGroupHealth] {
+		base.GET("/health", healthz)
+		base.GET("/healthz", healthz)
+		base.GET("/readyz", s.readyz)
+	}
+
+	// Set the debug endpoint
+	if enabled[RouteGroupDebug] {
+		base.GET("/debug", s.debugHandler)
+	}
+
+	// TibiaData API version 3 endpoints
+	if enabled[RouteGroupV3] {
+		base.GET("/v3/*action", func(c *gin.Context) {
+			c.JSON(299, gin.H{
+				"error": "TibiaData v3 is deprecated.",
+				"information": InformationV3{
+					APIversion: 3,
+					Timestamp:  TibiaDataDatetime(""),
+				},
+			})
+		})
+	}
+
+	// TibiaData API version 4 endpoints
+	if enabled[RouteGroupV4] {
+		v4 := base.Group("/v4")
+		if s.limiters != nil {
+			v4.Use(s.limiters.Middleware())
+		}
+		if s.apiKeys != nil {
+			v4.Use(s.apiKeys.Middleware())
+		}
+		{
+			// Tibia characters
+			v4.GET("/boostablebosses", s.tibiaBoostableBosses)
+
+			// Tibia characters
+			v4.GET("/character/:name", s.tibiaCharactersCharacter)
+
+			// Tibia creatures
+			v4.GET("/creature/:race", s.tibiaCreaturesCreature)
+			v4.GET("/creatures", s.tibiaCreaturesOverview)
+
+			// Tibia fansites
+			v4.GET("/fansites", s.tibiaFansites)
+
+			// Tibia guilds
+			v4.GET("/guild/:name", s.tibiaGuildsGuild)
+			// v4.GET("/guild/:name/events",TibiaGuildsGuildEvents)
+			// v4.GET("/guild/:name/wars",TibiaGuildsGuildWars)
+			v4.GET("/guilds/:world", s.tibiaGuildsOverview)
+
+			// Tibia highscores
+			v4.GET("/highscores/:world", func(c *gin.Context) {
+				c.Redirect(http.StatusMovedPermanently, v4.BasePath()+"/highscores/"+c.Param("world")+"/experience/"+TibiaDataDefaultVoc+"/1")
+			})
+			v4.GET("/highscores/:world/:category", func(c *gin.Context) {
+				c.Redirect(http.StatusMovedPermanently, v4.BasePath()+"/highscores/"+c.Param("world")+"/"+c.Param("category")+"/"+TibiaDataDefaultVoc+"/1")
+			})
+			v4.GET("/highscores/:world/:category/:vocation", s.tibiaHighscores)
+			v4.GET("/highscores/:world/:category/:vocation/:page", s.tibiaHighscores)
+
+			// Tibia houses
+			v4.GET("/house/:world/:house_id", s.tibiaHousesHouse)
+			v4.GET("/houses/:world/:town", s.tibiaHousesOverview)
+
+			// Tibia killstatistics
+			v4.GET("/killstatistics/:world", s.tibiaKillstatistics)
+
+			// Tibia news
+			v4.GET("/news/archive", s.tibiaNewslist)       // all categories (default 90 days)
+			v4.GET("/news/archive/:days", s.tibiaNewslist) // all categories
+			v4.GET("/news/id/:news_id", s.tibiaNews)       // shows one news entry
+			v4.GET("/news/latest", s.tibiaNewslist)        // only news and articles
+			v4.GET("/news/newsticker", s.tibiaNewslist)    // only news_ticker
+
+			// Tibia spells
+			v4.GET("/spell/:spell_id", s.tibiaSpellsSpell)
+			v4.GET("/spells", s.tibiaSpellsOverview)
+
+			// Tibia worlds
+			v4.GET("/world/:name", s.tibiaWorldsWorld)
+			v4.GET("/worlds", s.tibiaWorldsOverview)
+		}
+	}
+
+	// Admin endpoints (only available with API keys)
+	if enabled[RouteGroupAdmin] && s.apiKeys == nil {
+		log.Printf("[info] TibiaData API admin endpoints: disabled, they need API keys (TIBIADATA_API_KEYS_FILE or TIBIADATA_API_KEYS)")
+	} else if enabled[RouteGroupAdmin] {
+		admin := base.Group("/admin", s.apiKeys.AdminMiddleware())
+		admin.GET("/apikeys", s.apiKeysHandler)
+		admin.GET("/config", s.configHandler)
+		admin.GET("/status", s.adminStatusHandler)
+		admin.DELETE("/cache", s.adminCachePurgeHandler)
+		admin.POST("/mapping/reload", s.adminMappingReloadHandler)
+		admin.PUT("/debug", s.adminDebugHandler)
+	}
+
+	// Container version details endpoint
+	if enabled[RouteGroupVersions] {
+		base.GET("/versions", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{
+				"release": TibiaDataBuildRelease,
+				"build":   TibiaDataBuildBuilder,
+				"commit":  TibiaDataBuildCommit,
+				"edition": TibiaDataBuildEdition,
+			})
+		})
+	}
+
+	// Setting up the cache warmer if warmer.jobs (TIBIADATA_WARMER_JOBS) is set
+	// the paths of the jobs are relative to the base path
+	if len(config.Warmer.Jobs) > 0 {
+		warmerJobs, err := parseWarmerJobs(strings.Join(config.Warmer.Jobs, ";"))
+		if err != nil {
+			return nil, fmt.Errorf("cache warmer: %w", err)
+		}
+
+		s.warmer = newCacheWarmer(withPathPrefix(router, basePath), warmerJobs)
+		s.warmer.validator = s.validator
+		s.warmer.debug = s.debug
+		r.jobs = append(r.jobs, s.warmer.Run)
+		log.Printf("[info] TibiaData API cache warmer: %d jobs", len(warmerJobs))
+	}
+
+	// setting readyz endpoint to true
+	s.ready.Store(true)
+
+	return r, nil
+}
+
+// newConfigFetcher func - creates the pooled upstream fetcher with the upstream settings of config
+func newConfigFetcher(config *Config) (*tibiaDataFetcher, error) {
+	fetcher := newTibiaDataFetcher(config.Upstream.proxyDomain(), config.Upstream.MaxConnsPerHost)
+
+	// Setting up the pool of upstream proxies if proxies.list (TIBIADATA_PROXIES) is set
+	if config.Proxies.List != "" {
+		proxies, err := parseUpstreamProxies(config.Proxies.List)
+		if err != nil {
+			return nil, fmt.Errorf("proxy pool: %w", err)
+		}
+
+		fetcher.proxies = newProxyPool(
+			proxies,
+			config.Proxies.Selection,
+			config.Proxies.MaxFailures,
+			time.Duration(config.Proxies.EjectSeconds)*time.Second,
+		)
+		log.Printf("[info] TibiaData API proxy pool: %d proxies (%s)", len(proxies), fetcher.proxies.selection)
+	}
+
+	// Setting up record/replay of the pages of tibia.com if upstream.vcr_mode (TIBIADATA_VCR_MODE) is set
+	switch vcrMode := config.Upstream.VCRMode; vcrMode {
+	case vcrRecord, vcrReplay:
+		vcrDir := config.Upstream.VCRDir
+		fetcher.client.SetTransport(newVCRTransport(vcrMode, vcrDir, fetcher.client.GetClient().Transport))
+		log.Printf("[info] TibiaData API vcr: %s (%s)", vcrMode, vcrDir)
+	}
+
+	// Setting up the upstream rate limiter (requests per second to tibia.com, 0 disables it)
+	if rate := config.Upstream.Rate; rate > 0 {
+		fetcher.limiter = newUpstreamLimiter(
+			rate,
+			config.Upstream.Burst,
+			time.Duration(config.Upstream.MaxWaitSeconds)*time.Second,
+		)
+		log.Printf("[info] TibiaData API upstream rate limit: %.2f/s", rate)
+	}
+
+	// Setting up the upstream worker pool (concurrent requests to tibia.com, 0 disables it)
+	if workers := config.Upstream.Workers; workers > 0 {
+		fetcher.workers = newUpstreamWorkerPool(workers, config.Upstream.InteractiveWorkers)
+		log.Printf("[info] TibiaData API upstream workers: %d (%d reserved for API clients)", workers, fetcher.workers.reserved)
+	}
+
+	return fetcher, nil
+}
+
+// ServeHTTP func - serves the request with the routes of the API
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.engine.ServeHTTP(w, req)
+}
+
+// SetReady func - changes whether the router is reported as ready on /readyz
+// e.g. set it to false while the server is shutting down, so load balancers stop sending requests
+func (r *Router) SetReady(ready bool) {
+	r.server.ready.Store(ready)
+}
+
+// Run func - runs the background jobs until ctx is done and waits for them
+func (r *Router) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, job := range r.jobs {
+		wg.Add(1)
+		go func(job func(ctx context.Context)) {
+			defer wg.Done()
+			job(ctx)
+		}(job)
+	}
+
+	wg.Wait()
+}
+
+// withPathPrefix func - returns a handler serving the requests of handler with prefix added to their path
+func withPathPrefix(handler http.Handler, prefix string) http.Handler {
+	if prefix == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.URL.Path = prefix + req.URL.Path
+		handler.ServeHTTP(w, req)
+	})
+}
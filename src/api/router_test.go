@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/static"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRouter(t *testing.T) {
+	assert := assert.New(t)
+
+	file, err := static.TestFiles.Open("testdata/worlds/worlds.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetches := 0
+	router, err := NewRouter(RouterOptions{
+		BasePath:    "/tibiadata/",
+		RouteGroups: []string{RouteGroupPing, RouteGroupV4},
+		Middleware: []gin.HandlerFunc{func(c *gin.Context) {
+			c.Header("X-Gateway", "tibiadata")
+		}},
+		Fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			fetches++
+			return string(data), nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the router is mounted in-process under the mux of another server
+	mux := http.NewServeMux()
+	mux.Handle("/tibiadata/", router)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/tibiadata/v4/worlds")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("tibiadata", w.Header().Get("X-Gateway"))
+	assert.Equal(1, fetches)
+
+	var output WorldsOverviewResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(output.Worlds.RegularWorlds)
+
+	w = serve("/tibiadata/ping")
+	assert.Equal(http.StatusOK, w.Code)
+
+	// redirects stay below the base path
+	w = serve("/tibiadata/v4/highscores/Antica")
+	assert.Equal(http.StatusMovedPermanently, w.Code)
+	assert.Equal("/tibiadata/v4/highscores/Antica/experience/all/1", w.Header().Get("Location"))
+
+	// disabled route groups are not found
+	w = serve("/tibiadata/versions")
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	w = serve("/tibiadata/healthz")
+	assert.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestNewRouterBasePath(t *testing.T) {
+	assert := assert.New(t)
+
+	config := defaultConfig()
+	config.APIKeys.Keys = []APIKey{{Name: "worlds", Key: "worlds-key", Routes: []string{"/v4/worlds"}}}
+	config.Warmer.Jobs = []string{"@hourly /v4/worlds"}
+	config.Upstream.Proxy = "proxy.example.com"
+
+	router, err := NewRouter(RouterOptions{
+		Config:   &config,
+		BasePath: "/tibiadata",
+		Fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			return "", nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the routes of API keys are relative to the base path
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/tibiadata/v4/fansites", nil)
+	request.Header.Set("X-API-Key", "worlds-key")
+	router.ServeHTTP(w, request)
+	assert.Equal(http.StatusForbidden, w.Code)
+
+	// the paths of the cache warmer are relative to the base path
+	handled := ""
+	warmer := withPathPrefix(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled = r.URL.Path
+	}), "/tibiadata")
+	warmer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v4/worlds", nil))
+	assert.Equal("/tibiadata/v4/worlds", handled)
+	assert.NotNil(router.server.warmer)
+
+	// the router is ready once it is created
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tibiadata/readyz", nil))
+	assert.Equal(http.StatusOK, w.Code)
+
+	router.SetReady(false)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tibiadata/readyz", nil))
+	assert.Equal(http.StatusServiceUnavailable, w.Code)
+
+	// the upstream proxy is taken from the config
+	fetcher, err := newConfigFetcher(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("https://proxy.example.com/", fetcher.proxyDomain)
+
+	// invalid options are rejected
+	_, err = NewRouter(RouterOptions{RouteGroups: []string{"v5"}})
+	assert.ErrorContains(err, `route group "v5" is not one of`)
+
+	_, err = NewRouter(RouterOptions{BasePath: "tibiadata"})
+	assert.ErrorContains(err, "does not start with /")
+}
+
+func TestNewRouterSettings(t *testing.T) {
+	assert := assert.New(t)
+
+	fetcher := FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+		return "", nil
+	})
+
+	firstConfig := defaultConfig()
+	firstConfig.Debug = true
+	firstConfig.Upstream.ServerSaveMinutes = 5
+	firstConfig.Upstream.DeadlineSeconds = 3
+	firstConfig.APIKeys.Keys = []APIKey{{Name: "admin", Key: "admin-key", Admin: true}}
+
+	secondConfig := defaultConfig()
+	secondConfig.Upstream.DeadlineSeconds = 20
+
+	validator := validation.New()
+	first, err := NewRouter(RouterOptions{Config: &firstConfig, Fetcher: fetcher, Validator: validator})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewRouter(RouterOptions{Config: &secondConfig, Fetcher: fetcher})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// creating the second router does not change the settings of the first one
+	assert.True(first.server.debugMode().Load())
+	assert.False(second.server.debugMode().Load())
+	assert.Equal(5*time.Minute, first.server.serverSaveSchedule().duration)
+	assert.Equal(10*time.Minute, second.server.serverSaveSchedule().duration)
+	assert.Equal(3*time.Second, first.server.deadlineBudget("TibiaWorldsOverview"))
+	assert.Equal(20*time.Second, second.server.deadlineBudget("TibiaWorldsOverview"))
+	assert.Same(validator, first.server.validation())
+	assert.Same(validation.Default(), second.server.validation())
+
+	// the debug mode is only toggled for the router of the admin endpoint
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, "/admin/debug?enabled=false", nil)
+	request.Header.Set("X-API-Key", "admin-key")
+	first.ServeHTTP(w, request)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.False(first.server.debugMode().Load())
+
+	second.server.debugMode().Store(true)
+	assert.False(first.server.debugMode().Load())
+	assert.False(TibiaDataDebug.Load())
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"time"
+)
+
+// errServerSaveInProgress is the message of responses served from the stale store during the server save
+var errServerSaveInProgress = errors.New("server save in progress on tibia.com")
+
+// TibiaDataServerSave is the server save schedule of tibia.com outside of the routers
+// every router has a schedule of its own with the duration of TIBIADATA_SERVER_SAVE_MINUTES
+var TibiaDataServerSave = newServerSaveSchedule(10 * time.Minute)
+
+// serverSaveSchedule knows the daily server save of tibia.com at 10:00 CET/CEST
+type serverSaveSchedule struct {
+	location *time.Location
+	hour     int
+	duration time.Duration // how long tibia.com is offline after the server save starts
+
+	now func() time.Time
+}
+
+// newServerSaveSchedule func - creates a serverSaveSchedule with a window of duration
+func newServerSaveSchedule(duration time.Duration) *serverSaveSchedule {
+	// timezone used by tibia.com: CET/CEST
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		location = time.FixedZone("CET", 60*60)
+	}
+
+	return &serverSaveSchedule{
+		location: location,
+		hour:     10,
+		duration: duration,
+		now:      time.Now,
+	}
+}
+
+// last func - returns the start of the last server save before or at now
+func (s *serverSaveSchedule) last(now time.Time) time.Time {
+	now = now.In(s.location)
+
+	start := time.Date(now.Year(), now.Month(), now.Day(), s.hour, 0, 0, 0, s.location)
+	if start.After(now) {
+		start = time.Date(now.Year(), now.Month(), now.Day()-1, s.hour, 0, 0, 0, s.location)
+	}
+
+	return start
+}
+
+// Next func - returns the start of the next server save
+func (s *serverSaveSchedule) Next() time.Time {
+	last := s.last(s.now())
+
+	return time.Date(last.Year(), last.Month(), last.Day()+1, s.hour, 0, 0, 0, s.location)
+}
+
+// InProgress func - reports whether the server save is in progress
+func (s *serverSaveSchedule) InProgress() bool {
+	now := s.now()
+
+	return now.Sub(s.last(now)) < s.duration
+}
+
+// apply func - adds the server save details to the information block
+func (s *serverSaveSchedule) apply(information *Information) {
+	information.NextServerSave = s.Next().UTC().Format(time.RFC3339)
+	information.ServerSaveInProgress = s.InProgress()
+}
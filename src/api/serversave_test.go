@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerSaveSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	schedule := newServerSaveSchedule(10 * time.Minute)
+
+	// 10:00 CEST is 08:00 UTC
+	schedule.now = func() time.Time { return time.Date(2023, 7, 1, 7, 59, 0, 0, time.UTC) }
+	assert.Equal(time.Date(2023, 7, 1, 8, 0, 0, 0, time.UTC), schedule.Next().UTC())
+	assert.False(schedule.InProgress())
+
+	schedule.now = func() time.Time { return time.Date(2023, 7, 1, 8, 5, 0, 0, time.UTC) }
+	assert.Equal(time.Date(2023, 7, 2, 8, 0, 0, 0, time.UTC), schedule.Next().UTC())
+	assert.True(schedule.InProgress())
+
+	schedule.now = func() time.Time { return time.Date(2023, 7, 1, 8, 10, 0, 0, time.UTC) }
+	assert.False(schedule.InProgress())
+
+	// 10:00 CET is 09:00 UTC
+	schedule.now = func() time.Time { return time.Date(2023, 1, 15, 8, 30, 0, 0, time.UTC) }
+	assert.Equal(time.Date(2023, 1, 15, 9, 0, 0, 0, time.UTC), schedule.Next().UTC())
+
+	// the server save after the switch to summer time
+	schedule.now = func() time.Time { return time.Date(2023, 3, 25, 12, 0, 0, 0, time.UTC) }
+	assert.Equal(time.Date(2023, 3, 26, 8, 0, 0, 0, time.UTC), schedule.Next().UTC())
+
+	var information Information
+	schedule.apply(&information)
+	assert.Equal("2023-03-26T08:00:00Z", information.NextServerSave)
+	assert.False(information.ServerSaveInProgress)
+}
+
+func TestServerSaveServesStale(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(now func() time.Time) { TibiaDataServerSave.now = now }(TibiaDataServerSave.now)
+	TibiaDataServerSave.now = func() time.Time { return time.Date(2023, 7, 1, 7, 0, 0, 0, time.UTC) }
+
+	fetches := 0
+	var fetchErr error
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			fetches++
+			return "content", fetchErr
+		}),
+		stale:       newMemoryCache(10, 0),
+		staleMaxAge: time.Hour,
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return OutInformation{Information: Information{Timestamp: "2023-07-01T07:00:00Z", Status: Status{HTTPCode: http.StatusOK}}}, nil
+	}
+
+	serve := func() (*httptest.ResponseRecorder, OutInformation) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+		s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+
+		var output OutInformation
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatal(err)
+		}
+
+		return w, output
+	}
+
+	// responses carry the next server save
+	_, output := serve()
+	assert.Equal(1, fetches)
+	assert.Equal("2023-07-01T08:00:00Z", output.Information.NextServerSave)
+	assert.False(output.Information.ServerSaveInProgress)
+
+	// during the server save the last response is served without asking tibia.com
+	TibiaDataServerSave.now = func() time.Time { return time.Date(2023, 7, 1, 8, 2, 0, 0, time.UTC) }
+
+	w, output := serve()
+	assert.Equal(1, fetches)
+	assert.Equal(http.StatusOK, w.Code)
+	assert.True(output.Information.Stale)
+	assert.True(output.Information.ServerSaveInProgress)
+	assert.Equal("2023-07-02T08:00:00Z", output.Information.NextServerSave)
+	assert.Equal(errServerSaveInProgress.Error(), output.Information.Status.Message)
+
+	// without stale-if-error the last response is only served during the server save
+	TibiaDataServerSave.now = func() time.Time { return time.Date(2023, 7, 1, 9, 0, 0, 0, time.UTC) }
+	fetchErr = errors.New("connection reset")
+
+	w, output = serve()
+	assert.Equal(2, fetches)
+	assert.Equal(http.StatusBadGateway, w.Code)
+	assert.False(output.Information.Stale)
+}
+
+func TestServerSaveKeepsStale(t *testing.T) {
+	assert := assert.New(t)
+
+	newServer := func(config Config) *webServer {
+		router, err := NewRouter(RouterOptions{
+			Config: &config,
+			Fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+				return "", nil
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return router.server
+	}
+
+	// the last successful responses are kept for the server save by default
+	s := newServer(defaultConfig())
+	assert.NotNil(s.stale)
+	assert.False(s.staleIfError)
+
+	config := defaultConfig()
+	config.Upstream.ServerSaveMinutes = 0
+	s = newServer(config)
+	assert.Nil(s.stale)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TibiaDataStaleWarning is the Warning header value sent with stale responses
+const TibiaDataStaleWarning = `110 - "Response is Stale"`
+
+// storeStale func - keeps entry as the last successful response of key
+func (s *webServer) storeStale(key string, entry CacheEntry) {
+	if s.stale == nil {
+		return
+	}
+
+	s.stale.Set(key, entry, s.staleMaxAge)
+}
+
+// serveStale func - serves the last successful response of key during the server save or, with stale-if-error, after an upstream error
+// reports whether a stale response was found and served
+func (s *webServer) serveStale(c *gin.Context, key string, upstreamErr error, handlerName string) bool {
+	if s.stale == nil {
+		return false
+	}
+
+	entry, ok := s.stale.Get(key)
+	if !ok {
+		return false
+	}
+
+	TibiaDataAPIHandleStaleResponse(c, handlerName, entry, upstreamErr)
+
+	return true
+}
+
+// TibiaDataAPIHandleStaleResponse func - handling of stale responses served because tibia.com failed
+// The information block keeps the original timestamp and gets the stale flag and the upstream error
+func TibiaDataAPIHandleStaleResponse(c *gin.Context, s string, entry CacheEntry, upstreamErr error) {
+	now := time.Now()
+
+	data := entry.withInformation(func(information *Information) {
+		webServerOf(c).serverSaveSchedule().apply(information)
+		information.Stale = true
+		information.Status.Message = upstreamErr.Error()
+		information.Cache = &CacheInformation{
+			Hit: true,
+			Age: int(now.Sub(entry.StoredAt).Seconds()),
+		}
+	})
+
+	log.Printf("[warning] %s - (%s) served stale data from %s due to: %s", s, c.Request.RequestURI, entry.StoredAt.UTC().Format(time.RFC3339), upstreamErr)
+
+	// stale responses are served only until tibia.com answers again
+	entry.ExpiresAt = time.Time{}
+	TibiaDataSetCacheHeaders(c, entry, now)
+
+	c.Header("Warning", TibiaDataStaleWarning)
+	tibiaDataWriteSerializedResponse(c, s, data)
+}
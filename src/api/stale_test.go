@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaleIfError(t *testing.T) {
+	assert := assert.New(t)
+
+	var upstreamErr error
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			return "content", upstreamErr
+		}),
+		stale:        newMemoryCache(10, 0),
+		staleMaxAge:  time.Hour,
+		staleIfError: true,
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	requestHandler := func(BoxContentHTML string) (interface{}, error) {
+		return OutInformation{Information: Information{Timestamp: "2023-06-01T10:00:00Z", Status: Status{HTTPCode: http.StatusOK}}}, nil
+	}
+
+	serve := func() (*httptest.ResponseRecorder, OutInformation) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+		s.tibiaDataRequestHandler(c, request, requestHandler, "TibiaWorldsOverview")
+
+		var output OutInformation
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatal(err)
+		}
+
+		return w, output
+	}
+
+	// a successful response is served as usual
+	w, output := serve()
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Empty(w.Header().Get("Warning"))
+	assert.Equal("public, max-age=60", w.Header().Get("Cache-Control"))
+	assert.False(output.Information.Stale)
+	assert.Nil(output.Information.Cache)
+
+	// the last successful response is served when tibia.com is in maintenance
+	upstreamErr = validation.ErrorMaintenanceMode
+
+	w, output = serve()
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(TibiaDataStaleWarning, w.Header().Get("Warning"))
+	assert.Equal("no-cache", w.Header().Get("Cache-Control"))
+	assert.True(output.Information.Stale)
+	assert.Equal("2023-06-01T10:00:00Z", output.Information.Timestamp)
+	assert.Equal(validation.ErrorMaintenanceMode.Error(), output.Information.Status.Message)
+	assert.True(output.Information.Cache.Hit)
+
+	// without a previous response the error is returned
+	request.URL = "https://www.tibia.com/community/?subtopic=worlds&world=Antica"
+	upstreamErr = errors.New("request throttled")
+
+	w, output = serve()
+	assert.Equal(http.StatusBadGateway, w.Code)
+	assert.Equal("no-store", w.Header().Get("Cache-Control"))
+	assert.False(output.Information.Stale)
+}
+
+func TestStaleIfErrorParserErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			return "content", nil
+		}),
+		stale:        newMemoryCache(10, 0),
+		staleMaxAge:  time.Hour,
+		staleIfError: true,
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=characters&name=Durin"}
+	s.storeStale(request.Key(), CacheEntry{Data: []byte(`{"information":{}}`), StoredAt: time.Now()})
+
+	// errors of the parser are not upstream errors and are not hidden
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v4/character/Durin", nil)
+
+	s.tibiaDataRequestHandler(c, request, func(BoxContentHTML string) (interface{}, error) {
+		return nil, validation.ErrorCharacterNotFound
+	}, "TibiaCharactersCharacter")
+
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Empty(w.Header().Get("Warning"))
+}
+
+func TestStaleIfErrorStore(t *testing.T) {
+	assert := assert.New(t)
+
+	config := defaultConfig()
+	config.Cache.Backend = "memory"
+	config.Cache.StaleIfError = true
+	config.Cache.StaleMaxEntries = 1
+
+	router, err := NewRouter(RouterOptions{
+		Config: &config,
+		Fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			return "", nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := router.server
+
+	// the last successful responses are kept apart from the cache with a limit of their own
+	s.storeStale("GET https://www.tibia.com/community/?subtopic=worlds", CacheEntry{Data: []byte("data")})
+	s.storeStale("GET https://www.tibia.com/community/?subtopic=worlds&world=Antica", CacheEntry{Data: []byte("data")})
+	assert.Equal(0, s.cache.Stats().Entries)
+	assert.Equal(1, s.stale.Stats().Entries)
+	assert.Equal(1, s.runtimeStatus().Stale.Entries)
+}
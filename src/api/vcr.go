@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/TibiaData/tibiadata-api-go/src/static"
+)
+
+// the modes of the vcrTransport
+const (
+	vcrRecord = "record" // responses of tibia.com are stored on disk
+	vcrReplay = "replay" // responses are served from disk instead of tibia.com
+)
+
+// vcrTransport records and replays the pages of tibia.com
+// the files use the layout of src/static/testdata, e.g. worlds/world/Antica.html
+//
+// A recording keeps the body as sent by tibia.com (usually ISO-8859-1) with the status and headers
+// in a .json file next to it, so maintenance redirects and throttled requests are replayed too.
+// Pages without a .json file (like the test files) are replayed as UTF-8 with 200.
+type vcrTransport struct {
+	mode string
+	dir  string
+	next http.RoundTripper // the transport to tibia.com (used when recording)
+}
+
+// vcrResponse is the status and headers of a recorded response
+type vcrResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+}
+
+// newVCRTransport func - creates a vcrTransport storing the pages in dir
+func newVCRTransport(mode, dir string, next http.RoundTripper) *vcrTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &vcrTransport{
+		mode: mode,
+		dir:  dir,
+		next: next,
+	}
+}
+
+// RoundTrip func - replays the request from disk or sends it and records the response
+func (v *vcrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the form data of POST requests is part of the key
+	form := url.Values{}
+	if req.Method == http.MethodPost && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		form, _ = url.ParseQuery(string(body))
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	file := filepath.Join(v.dir, filepath.FromSlash(static.TestFilePath(req.URL.Query(), form)))
+
+	if v.mode == vcrReplay {
+		return v.replay(req, file)
+	}
+
+	res, err := v.next.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	// failing to record does not fail the request
+	if err := v.record(file, res.StatusCode, res.Header, data); err != nil {
+		log.Printf("[warning] TibiaData API vcr: recording of %s failed: %s", req.URL, err)
+	}
+
+	return res, nil
+}
+
+// replay func - returns the response recorded at file
+func (v *vcrTransport) replay(req *http.Request, file string) (*http.Response, error) {
+	data, err := os.ReadFile(file + ".html")
+	if err != nil {
+		return nil, fmt.Errorf("no recording of %s: %w", req.URL, err)
+	}
+
+	recorded := vcrResponse{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/html; charset=UTF-8"}},
+	}
+	if meta, err := os.ReadFile(file + ".json"); err == nil {
+		if err := json.Unmarshal(meta, &recorded); err != nil {
+			return nil, fmt.Errorf("invalid recording of %s: %w", req.URL, err)
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header,
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// record func - stores the body of a response at file and its status and headers next to it
+func (v *vcrTransport) record(file string, statusCode int, header http.Header, data []byte) error {
+	meta, err := json.MarshalIndent(vcrResponse{StatusCode: statusCode, Header: header}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file+".html", data, 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file+".json", meta, 0o644)
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TibiaData/tibiadata-api-go/src/static"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVCRPath(t *testing.T) {
+	assert := assert.New(t)
+
+	paths := map[string]string{
+		"https://www.tibia.com/community/?subtopic=worlds":                                                         "worlds/worlds",
+		"https://www.tibia.com/community/?subtopic=worlds&world=Premia":                                            "worlds/world/Premia",
+		"https://www.tibia.com/community/?subtopic=characters&name=Riley+No+Hands":                                 "characters/Riley No Hands",
+		"https://www.tibia.com/community/?subtopic=guilds&page=view&GuildName=Kotki+Antica":                        "guilds/guild/Kotki Antica",
+		"https://www.tibia.com/community/?subtopic=guilds&world=Premia":                                            "guilds/Premia",
+		"https://www.tibia.com/community/?subtopic=houses&world=Antica&town=Thais&type=houses":                     "houses/overview/AnticaThaisHouses",
+		"https://www.tibia.com/community/?subtopic=houses&world=Premia&town=Edron&type=guildhalls":                 "houses/overview/PremiaEdronGuilds",
+		"https://www.tibia.com/community/?subtopic=houses&page=view&world=Premia&houseid=35019":                    "houses/Premia/35019",
+		"https://www.tibia.com/community/?subtopic=highscores&world=&category=6&profession=0&currentpage=2":        "highscores/all/6-0-2",
+		"https://www.tibia.com/community/?subtopic=highscores&world=Vunira&category=10&profession=5&currentpage=3": "highscores/Vunira/10-5-3",
+		"https://www.tibia.com/community/?subtopic=killstatistics&world=Antica":                                    "killstatistics/Antica",
+		"https://www.tibia.com/library/?subtopic=creatures&race=demon":                                             "creatures/creature/demon",
+		"https://www.tibia.com/library/?subtopic=spells&vocation=":                                                 "spells/overviewall",
+		"https://www.tibia.com/library/?subtopic=spells&vocation=Druid":                                            "spells/overviewdruid",
+		"https://www.tibia.com/news/?subtopic=newsarchive&id=6512":                                                 "news/archive/6512",
+		"https://www.tibia.com/community/?subtopic=characters&name=..%2F..%2Fetc":                                  "characters/.._.._etc",
+
+		// the test files saved by hand keep their names
+		"https://www.tibia.com/community/?subtopic=highscores&world=&category=6&profession=0&currentpage=1":        "highscores/all",
+		"https://www.tibia.com/community/?subtopic=highscores&world=Vunira&category=10&profession=5&currentpage=4": "highscores/loyalty",
+		"https://www.tibia.com/community/?subtopic=houses&page=view&world=Premia&houseid=54025":                    "houses/Premia/Edron/Cormaya10",
+		"https://www.tibia.com/library/?subtopic=creatures&race=lavablob":                                          "creatures/creature/lava lurkers",
+		"https://www.tibia.com/library/?subtopic=spells&spell=heavymagicmissilerune":                               "spells/spell/Heavy Magic Missile Rune",
+	}
+
+	for rawURL, path := range paths {
+		requestURL, err := url.Parse(rawURL)
+		assert.Nil(err)
+		assert.Equal(path, static.TestFilePath(requestURL.Query(), url.Values{}), rawURL)
+	}
+
+	// news lists are told apart by their period and filters
+	period := url.Values{
+		"filter_begin_day": {"14"}, "filter_begin_month": {"12"}, "filter_begin_year": {"2021"},
+		"filter_end_day": {"13"}, "filter_end_month": {"1"}, "filter_end_year": {"2022"},
+	}
+	withFilters := func(filters ...string) url.Values {
+		form := url.Values{}
+		for field, values := range period {
+			form[field] = values
+		}
+		for _, filter := range filters {
+			form.Set("filter_"+filter, filter)
+		}
+		return form
+	}
+	newslist := url.Values{"subtopic": {"newsarchive"}}
+
+	assert.Equal("news/newslist/2021-12-14_2022-01-13-article-news-ticker", static.TestFilePath(newslist, withFilters("ticker", "news", "article")))
+	assert.Equal("news/newslist/2021-12-14_2022-01-13-article-news", static.TestFilePath(newslist, withFilters("news", "article")))
+	assert.Equal("news/newslist/2021-12-14_2022-01-13-ticker", static.TestFilePath(newslist, withFilters("ticker")))
+
+	period.Set("filter_begin_year", "2020")
+	assert.Equal("news/newslist/2020-12-14_2022-01-13-ticker", static.TestFilePath(newslist, withFilters("ticker")))
+}
+
+func TestVCRReplayTestdata(t *testing.T) {
+	assert := assert.New(t)
+
+	fetcher := newTibiaDataFetcher("", 2)
+	fetcher.client.SetTransport(newVCRTransport(vcrReplay, filepath.Join("..", "static", "testdata"), nil))
+
+	data, err := fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds&world=Premia"})
+	assert.Nil(err)
+	assert.Contains(data, "Premia")
+
+	_, err = fetcher.Fetch(context.Background(), TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds&world=Nowhere"})
+	assert.NotNil(err)
+
+	// the requests of the handlers match the names of the test files
+	for _, request := range []TibiaDataRequestStruct{
+		{URL: "https://www.tibia.com/community/?subtopic=houses&page=view&world=Premia&houseid=54025"},
+		{URL: "https://www.tibia.com/library/?subtopic=creatures&race=lavablob"},
+		{URL: "https://www.tibia.com/library/?subtopic=spells&spell=heavymagicmissilerune"},
+		{URL: "https://www.tibia.com/community/?subtopic=highscores&world=&category=6&profession=0&currentpage=1"},
+		{URL: "https://www.tibia.com/community/?subtopic=highscores&world=Vunira&category=10&profession=5&currentpage=4"},
+		{
+			Method: http.MethodPost,
+			URL:    "https://www.tibia.com/news/?subtopic=newsarchive",
+			FormData: map[string]string{
+				"filter_begin_day": "14", "filter_begin_month": "12", "filter_begin_year": "2021",
+				"filter_end_day": "13", "filter_end_month": "1", "filter_end_year": "2022",
+				"filter_cipsoft": "cipsoft", "filter_community": "community", "filter_development": "development",
+				"filter_support": "support", "filter_technical": "technical",
+				"filter_ticker": "ticker", "filter_article": "article", "filter_news": "news",
+			},
+		},
+	} {
+		_, err := fetcher.Fetch(context.Background(), request)
+		assert.Nil(err, request.URL)
+	}
+}
+
+func TestVCRRecordAndReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = r.ParseForm()
+		_, _ = w.Write([]byte(`<div class="Border_2"><div class="Border_3">` + r.PostForm.Get("filter_news") + `</div></div>`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	request := TibiaDataRequestStruct{Method: http.MethodPost, URL: "https://www.tibia.com/news/?subtopic=newsarchive", FormData: map[string]string{
+		"filter_begin_day": "14", "filter_begin_month": "12", "filter_begin_year": "2021",
+		"filter_end_day": "13", "filter_end_month": "1", "filter_end_year": "2022",
+		"filter_news": "news",
+	}}
+
+	recorder := newTibiaDataFetcher(server.URL+"/", 2)
+	recorder.client.SetTransport(newVCRTransport(vcrRecord, dir, recorder.client.GetClient().Transport))
+
+	data, err := recorder.Fetch(context.Background(), request)
+	assert.Nil(err)
+	assert.Equal("news", data)
+	assert.FileExists(filepath.Join(dir, "news", "newslist", "2021-12-14_2022-01-13-news.html"))
+	assert.FileExists(filepath.Join(dir, "news", "newslist", "2021-12-14_2022-01-13-news.json"))
+
+	// the recording is served without going to tibia.com
+	player := newTibiaDataFetcher(server.URL+"/", 2)
+	player.client.SetTransport(newVCRTransport(vcrReplay, dir, nil))
+
+	data, err = player.Fetch(context.Background(), request)
+	assert.Nil(err)
+	assert.Equal("news", data)
+	assert.Equal(1, requests)
+}
+
+func TestVCRRecordErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("world") {
+		case "Antica":
+			http.Redirect(w, r, "https://maintenance.tibia.com/", http.StatusFound)
+		case "Premia":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			// the raw bytes of ISO-8859-1 pages are recorded
+			w.Header().Set("Content-Type", "text/html; charset=ISO-8859-1")
+			_, _ = w.Write(static.EncodeLatin1([]byte(`<div class="Border_2"><div class="Border_3">Torbjörn</div></div>`)))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder := newTibiaDataFetcher(server.URL+"/", 2)
+	recorder.client.SetTransport(newVCRTransport(vcrRecord, dir, recorder.client.GetClient().Transport))
+	player := newTibiaDataFetcher(server.URL+"/", 2)
+	player.client.SetTransport(newVCRTransport(vcrReplay, dir, nil))
+
+	maintenance := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds&world=Antica"}
+	throttled := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds&world=Premia"}
+	latin1 := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds&world=Vunira"}
+
+	for _, fetcher := range []*tibiaDataFetcher{recorder, player} {
+		_, err := fetcher.Fetch(context.Background(), maintenance)
+		assert.ErrorIs(err, validation.ErrorMaintenanceMode)
+
+		_, err = fetcher.Fetch(context.Background(), throttled)
+		assert.ErrorIs(err, validation.ErrorUpstreamThrottled)
+
+		data, err := fetcher.Fetch(context.Background(), latin1)
+		assert.Nil(err)
+		assert.Equal("Torbjörn", data)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "worlds", "world", "Vunira.html"))
+	assert.Nil(err)
+	assert.Contains(string(data), "Torbj\xf6rn")
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+)
+
+// WarmerJobStatus is the state of a cache warmer job shown on /debug
+type WarmerJobStatus struct {
+	Schedule     string `json:"schedule"`             // The cron expression of the job.
+	Path         string `json:"path"`                 // The path of the endpoint warmed by the job.
+	Running      bool   `json:"running"`              // Whether the job is running right now.
+	LastRun      string `json:"last_run,omitempty"`   // When the job ran the last time.
+	LastDuration int64  `json:"last_duration_ms"`     // How long the last run took in milliseconds.
+	LastSuccess  bool   `json:"last_success"`         // Whether all requests of the last run succeeded.
+	LastError    string `json:"last_error,omitempty"` // The first failure of the last run.
+	NextRun      string `json:"next_run,omitempty"`   // When the job runs the next time.
+	Successes    uint64 `json:"successes"`            // The number of successful runs.
+	Failures     uint64 `json:"failures"`             // The number of failed runs.
+}
+
+type cacheRefreshKey struct{}
+
+// withCacheRefresh func - returns a copy of ctx whose requests skip the cache lookup, so the cached response is replaced
+func withCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheRefreshKey{}, true)
+}
+
+// isCacheRefresh func - reports whether the requests of ctx skip the cache lookup
+func isCacheRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(cacheRefreshKey{}).(bool)
+	return refresh
+}
+
+// warmerJob requests an endpoint on a schedule, so its response is in the cache before clients ask for it
+type warmerJob struct {
+	expr     string
+	path     string // may contain {world}, which is replaced with every world
+	schedule cronSchedule
+
+	mu     sync.Mutex
+	status WarmerJobStatus
+}
+
+// parseWarmerJobs func - parses jobs separated by ";" in the format "<cron expression> <path>"
+// for example: "*/5 * * * * /v4/worlds;5 10 * * * /v4/boostablebosses;@every 1h /v4/highscores/{world}/experience/all/1"
+func parseWarmerJobs(value string) ([]*warmerJob, error) {
+	var jobs []*warmerJob
+
+	for _, entry := range strings.Split(value, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+
+		path := fields[len(fields)-1]
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("warmer job %q does not end with a path", entry)
+		}
+
+		expr := strings.Join(fields[:len(fields)-1], " ")
+		schedule, err := parseCronSchedule(expr)
+		if err != nil {
+			return nil, fmt.Errorf("warmer job %q: %w", entry, err)
+		}
+
+		jobs = append(jobs, &warmerJob{
+			expr:     expr,
+			path:     path,
+			schedule: schedule,
+			status:   WarmerJobStatus{Schedule: expr, Path: path},
+		})
+	}
+
+	return jobs, nil
+}
+
+// cacheWarmer runs the warmer jobs against the router of the API
+// the requests use the background priority, so they never delay requests of clients
+type cacheWarmer struct {
+	handler   http.Handler
+	jobs      []*warmerJob
+	validator *validation.Validator // expands {world} in the paths of the jobs
+	debug     *atomic.Bool          // logs the finished jobs
+	now       func() time.Time
+}
+
+// newCacheWarmer func - creates a cacheWarmer sending the requests of jobs to handler
+func newCacheWarmer(handler http.Handler, jobs []*warmerJob) *cacheWarmer {
+	return &cacheWarmer{
+		handler:   handler,
+		jobs:      jobs,
+		validator: validation.Default(),
+		debug:     &TibiaDataDebug,
+		now:       time.Now,
+	}
+}
+
+// Run func - runs every job on its schedule until ctx is done
+func (w *cacheWarmer) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, job := range w.jobs {
+		wg.Add(1)
+		go func(job *warmerJob) {
+			defer wg.Done()
+
+			for {
+				next := job.schedule.Next(w.now())
+				if next.IsZero() {
+					log.Printf("[warning] TibiaData API cache warmer: job %s %s never runs", job.expr, job.path)
+					return
+				}
+
+				job.mu.Lock()
+				job.status.NextRun = next.UTC().Format(time.RFC3339)
+				job.mu.Unlock()
+
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-timer.C:
+					w.runJob(ctx, job)
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		}(job)
+	}
+
+	wg.Wait()
+}
+
+// runJob func - requests every path of job and records the result
+func (w *cacheWarmer) runJob(ctx context.Context, job *warmerJob) {
+	start := w.now()
+
+	job.mu.Lock()
+	job.status.Running = true
+	job.mu.Unlock()
+
+	var firstErr error
+
+	paths, err := expandWarmerPath(job.path, w.validator)
+	if err != nil {
+		firstErr = err
+	}
+
+	ctx = withUpstreamPriority(withCacheRefresh(ctx), upstreamPriorityBackground)
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			firstErr = err
+			break
+		}
+
+		response := &warmerResponseWriter{header: http.Header{}, status: http.StatusOK}
+		w.handler.ServeHTTP(response, request)
+
+		if response.status != http.StatusOK && firstErr == nil {
+			firstErr = fmt.Errorf("%s returned %d", path, response.status)
+		}
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	job.status.Running = false
+	job.status.LastRun = start.UTC().Format(time.RFC3339)
+	job.status.LastDuration = w.now().Sub(start).Milliseconds()
+	job.status.LastSuccess = firstErr == nil
+	job.status.LastError = ""
+
+	if firstErr != nil {
+		job.status.Failures++
+		job.status.LastError = firstErr.Error()
+		log.Printf("[warning] TibiaData API cache warmer: job %s %s failed: %s", job.expr, job.path, firstErr)
+		return
+	}
+
+	job.status.Successes++
+	if w.debug.Load() {
+		log.Printf("[info] TibiaData API cache warmer: job %s %s finished in %s", job.expr, job.path, w.now().Sub(start))
+	}
+}
+
+// expandWarmerPath func - returns path with {world} replaced with every world of validator
+func expandWarmerPath(path string, validator *validation.Validator) ([]string, error) {
+	if !strings.Contains(path, "{world}") {
+		return []string{path}, nil
+	}
+
+	worlds, err := validator.GetWorlds()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(worlds))
+	for _, world := range worlds {
+		paths = append(paths, strings.ReplaceAll(path, "{world}", url.PathEscape(world)))
+	}
+
+	return paths, nil
+}
+
+// Status func - returns the state of every job
+func (w *cacheWarmer) Status() []WarmerJobStatus {
+	status := make([]WarmerJobStatus, 0, len(w.jobs))
+
+	for _, job := range w.jobs {
+		job.mu.Lock()
+		status = append(status, job.status)
+		job.mu.Unlock()
+	}
+
+	return status
+}
+
+// warmerResponseWriter is a http.ResponseWriter keeping only the status of a response
+type warmerResponseWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *warmerResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *warmerResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *warmerResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWarmerJobs(t *testing.T) {
+	assert := assert.New(t)
+
+	jobs, err := parseWarmerJobs("*/5 * * * * /v4/worlds; @every 1h /v4/highscores/{world}/experience/all/1;")
+	assert.Nil(err)
+	assert.Len(jobs, 2)
+	assert.Equal("*/5 * * * *", jobs[0].expr)
+	assert.Equal("/v4/worlds", jobs[0].path)
+	assert.Equal("@every 1h", jobs[1].expr)
+
+	_, err = parseWarmerJobs("*/5 * * * *")
+	assert.NotNil(err)
+
+	_, err = parseWarmerJobs("61 * * * * /v4/worlds")
+	assert.NotNil(err)
+
+	// {world} is replaced with every world
+	paths, err := expandWarmerPath("/v4/highscores/{world}/experience/all/1", validation.Default())
+	assert.Nil(err)
+	assert.Contains(paths, "/v4/highscores/Antica/experience/all/1")
+}
+
+func TestCacheWarmer(t *testing.T) {
+	assert := assert.New(t)
+
+	var priorities []upstreamPriority
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			priorities = append(priorities, upstreamPriorityFromContext(ctx))
+			return "content", nil
+		}),
+		cache: newMemoryCache(10, 0),
+	}
+
+	router := gin.New()
+	router.GET("/v4/worlds", func(c *gin.Context) {
+		s.tibiaDataRequestHandler(c, TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}, func(BoxContentHTML string) (interface{}, error) {
+			return OutInformation{Information: Information{Status: Status{HTTPCode: http.StatusOK}}}, nil
+		}, "TibiaWorldsOverview")
+	})
+
+	jobs, err := parseWarmerJobs("* * * * * /v4/worlds;* * * * * /v4/missing")
+	assert.Nil(err)
+
+	warmer := newCacheWarmer(router, jobs)
+	warmer.runJob(context.Background(), jobs[0])
+	warmer.runJob(context.Background(), jobs[1])
+
+	// the response is in the cache for the next client
+	_, ok := s.cache.Get(TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}.Key())
+	assert.True(ok)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v4/worlds", nil))
+	assert.Contains(w.Body.String(), `"hit":true`)
+
+	// the next run replaces the cached response
+	warmer.runJob(context.Background(), jobs[0])
+	assert.Equal([]upstreamPriority{upstreamPriorityBackground, upstreamPriorityBackground}, priorities)
+
+	status := warmer.Status()
+	assert.Len(status, 2)
+	assert.True(status[0].LastSuccess)
+	assert.Equal(uint64(2), status[0].Successes)
+	assert.NotEmpty(status[0].LastRun)
+	assert.False(status[1].LastSuccess)
+	assert.Equal(uint64(1), status[1].Failures)
+	assert.Contains(status[1].LastError, "404")
+
+	// the jobs run on their schedule until the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	warmer.jobs = []*warmerJob{{expr: "@every 10ms", path: "/v4/worlds", schedule: everySchedule(10 * time.Millisecond)}}
+	go warmer.Run(ctx)
+	assert.Eventually(func() bool { return warmer.Status()[0].Successes > 0 }, time.Second, 5*time.Millisecond)
+	cancel()
+}
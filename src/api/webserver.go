@@ -1,31 +1,35 @@
-package main
+package api
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/TibiaData/tibiadata-api-go/src/validation"
 	_ "github.com/mantyr/go-charset/data"
+	"golang.org/x/sync/singleflight"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
-	"github.com/PuerkitoBio/goquery"
-	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	"github.com/go-resty/resty/v2"
 )
 
 var (
 	// TibiaData app resty vars
-	TibiaDataUserAgent, TibiaDataProxyDomain string
+	TibiaDataUserAgent string
 
 	// ErrorNotFound will be returned if the requests ends up in a 404
 	ErrorNotFound = errors.New("page not found")
@@ -44,9 +48,14 @@ type OutInformation struct {
 
 // Information stores some API related data
 type Information struct {
-	APIDetails APIDetails `json:"api"`       // The API details.
-	Timestamp  string     `json:"timestamp"` // The timestamp from when the data was processed.
-	Status     Status     `json:"status"`    // The response status information.
+	APIDetails APIDetails        `json:"api"`             // The API details.
+	Timestamp  string            `json:"timestamp"`       // The timestamp from when the data was processed.
+	Status     Status            `json:"status"`          // The response status information.
+	Cache      *CacheInformation `json:"cache,omitempty"` // The cache information (only when caching is enabled).
+	Stale      bool              `json:"stale,omitempty"` // Whether the data is stale, because tibia.com could not be reached.
+
+	NextServerSave       string `json:"next_server_save,omitempty"`        // The start of the next server save of tibia.com.
+	ServerSaveInProgress bool   `json:"server_save_in_progress,omitempty"` // Whether the server save of tibia.com is in progress.
 }
 
 // API details store information about this API
@@ -70,11 +79,114 @@ type TibiaDataRequestStruct struct {
 	FormData map[string]string `json:"form_data"` // Request form content (used when POST)
 }
 
+// Key func - returns a string identifying the request (method, url and sorted form data)
+func (r TibiaDataRequestStruct) Key() string {
+	method := r.Method
+	if method == "" {
+		method = resty.MethodGet
+	}
+
+	// sorting the form data so the key is stable
+	formKeys := make([]string, 0, len(r.FormData))
+	for k := range r.FormData {
+		formKeys = append(formKeys, k)
+	}
+	sort.Strings(formKeys)
+
+	key := method + " " + r.URL
+	for _, k := range formKeys {
+		key += "&" + url.QueryEscape(k) + "=" + url.QueryEscape(r.FormData[k])
+	}
+
+	return key
+}
+
+// webServer holds the dependencies shared by the handlers
+type webServer struct {
+	config   *Config             // the settings the server was started with
+	fetcher  Fetcher             // used to retrieve pages from tibia.com
+	cache    ResponseCache       // stores responses (nil if caching is disabled)
+	requests singleflight.Group  // coalesces concurrent identical requests
+	flights  flights             // the contexts of the coalesced requests
+	circuit  *upstreamCircuit    // tracks the state of tibia.com (nil if disabled)
+	proxies  *proxyPool          // the upstream proxies used by the fetcher (nil if not set)
+	workers  *upstreamWorkerPool // the upstream worker pool used by the fetcher (nil if not set)
+	warmer   *cacheWarmer        // pre-fetches responses into the cache (nil if not set)
+	apiKeys  *apiKeyStore        // authenticates requests by API key (nil if not set)
+	limiters *clientLimiters     // limits the requests per client IP (nil if not set)
+	ready    atomic.Bool         // whether /readyz reports the server as ready
+
+	stale        ResponseCache // stores the last successful responses (nil if stale-if-error and the server save are disabled)
+	staleMaxAge  time.Duration // how long the last successful responses are kept
+	staleIfError bool          // whether the last successful responses are served when tibia.com fails
+
+	validator       *validation.Validator // validates the requests (the default validator if not set)
+	serverSave      *serverSaveSchedule   // the server save window of tibia.com (TibiaDataServerSave if not set)
+	defaultDeadline time.Duration         // the deadline budget of the handlers (TibiaDataDefaultDeadlineBudget if not set)
+	debug           *atomic.Bool          // logs much more details (TibiaDataDebug if not set)
+	upstreamCharset *tibiaDataCharset     // the charset of tibia.com known to the fetcher (ISO-8859-1 if not set)
+}
+
+// webServerContextKey is the key of the webServer handling a request in the gin context
+const webServerContextKey = "tibiadata.webserver"
+
+// webServerOf func - returns the webServer handling c
+// outside of a router the webServer has the process-wide settings
+func webServerOf(c *gin.Context) *webServer {
+	if s, ok := c.Get(webServerContextKey); ok {
+		return s.(*webServer)
+	}
+
+	return &webServer{}
+}
+
+// withWebServer func - stores s in the gin context, so the responses of a router are written with its settings
+func (s *webServer) withWebServer(c *gin.Context) {
+	c.Set(webServerContextKey, s)
+	c.Next()
+}
+
+// validation func - returns the validator of the requests
+func (s *webServer) validation() *validation.Validator {
+	if s.validator != nil {
+		return s.validator
+	}
+
+	return validation.Default()
+}
+
+// serverSaveSchedule func - returns the server save schedule of tibia.com
+func (s *webServer) serverSaveSchedule() *serverSaveSchedule {
+	if s.serverSave != nil {
+		return s.serverSave
+	}
+
+	return TibiaDataServerSave
+}
+
+// debugMode func - returns the debug mode
+func (s *webServer) debugMode() *atomic.Bool {
+	if s.debug != nil {
+		return s.debug
+	}
+
+	return &TibiaDataDebug
+}
+
+// charset func - returns the charset the queries to tibia.com are encoded in
+func (s *webServer) charset() *tibiaDataCharset {
+	if s.upstreamCharset != nil {
+		return s.upstreamCharset
+	}
+
+	return tibiaDataDefaultCharset
+}
+
 // RunWebServer starts the gin server
 // It blocks the code and will only finish execution on shutdown
-func runWebServer() {
-	// Setting gin-application to certain mode if GIN_MODE is set to release, test or debug (default is release)
-	switch ginMode := getEnv("GIN_MODE", "release"); ginMode {
+func RunWebServer(config *Config) {
+	// Setting gin-application to certain mode if gin_mode (GIN_MODE) is set to release, test or debug (default is release)
+	switch config.GinMode {
 	case "test":
 		gin.SetMode(gin.TestMode)
 	case "debug":
@@ -86,163 +198,51 @@ func runWebServer() {
 	// Logging the gin.mode
 	log.Printf("[info] TibiaData API gin-mode: %s", gin.Mode())
 
-	// Starting an Engine instance
-	router := gin.Default()
-
-	// Gin middleware to enable GZIP support
-	router.Use(gzip.Gzip(gzip.DefaultCompression))
-
-	// Set 404 not found page
-	router.NoRoute(func(c *gin.Context) {
-		TibiaDataErrorHandler(
-			c,
-			ErrorNotFound,
-			http.StatusNotFound,
-		)
-	})
-
-	// Set proxy feature of gin
-	if isEnvExist("GIN_TRUSTED_PROXIES") {
-		trustedProxies := getEnv("GIN_TRUSTED_PROXIES", "")
-		_ = router.SetTrustedProxies(strings.Split(trustedProxies, ","))
-		log.Printf("[info] TibiaData API gin-trusted-proxies: %s", strings.Split(trustedProxies, ","))
-	} else {
-		_ = router.SetTrustedProxies(nil)
-	}
-
-	// Set the ping endpoint
-	router.GET("/ping", func(c *gin.Context) {
-		data := Information{
-			APIDetails: TibiaDataAPIDetails,
-			Timestamp:  TibiaDataDatetime(""),
-			Status: Status{
-				HTTPCode: http.StatusOK,
-				Message:  "pong",
-			},
-		}
-
-		var output OutInformation
-		output.Information = data
-
-		c.JSON(http.StatusOK, output)
-	})
-
-	// health endpoints for kubernetes
-	router.GET("/health", healthz)
-	router.GET("/healthz", healthz)
-	router.GET("/readyz", readyz)
-
-	// Set the debug endpoint
-	router.GET("/debug", debugHandler)
-
-	// TibiaData API version 3 endpoints
-	router.GET("/v3/*action", func(c *gin.Context) {
-		c.JSON(299, gin.H{
-			"error": "TibiaData v3 is deprecated.",
-			"information": InformationV3{
-				APIversion: 3,
-				Timestamp:  TibiaDataDatetime(""),
-			},
-		})
-	})
-
-	// TibiaData API version 4 endpoints
-	v4 := router.Group("/v4")
-	{
-		// Tibia characters
-		v4.GET("/boostablebosses", tibiaBoostableBosses)
-
-		// Tibia characters
-		v4.GET("/character/:name", tibiaCharactersCharacter)
-
-		// Tibia creatures
-		v4.GET("/creature/:race", tibiaCreaturesCreature)
-		v4.GET("/creatures", tibiaCreaturesOverview)
-
-		// Tibia fansites
-		v4.GET("/fansites", tibiaFansites)
-
-		// Tibia guilds
-		v4.GET("/guild/:name", tibiaGuildsGuild)
-		// v4.GET("/guild/:name/events",TibiaGuildsGuildEvents)
-		// v4.GET("/guild/:name/wars",TibiaGuildsGuildWars)
-		v4.GET("/guilds/:world", tibiaGuildsOverview)
-
-		// Tibia highscores
-		v4.GET("/highscores/:world", func(c *gin.Context) {
-			c.Redirect(http.StatusMovedPermanently, v4.BasePath()+"/highscores/"+c.Param("world")+"/experience/"+TibiaDataDefaultVoc+"/1")
-		})
-		v4.GET("/highscores/:world/:category", func(c *gin.Context) {
-			c.Redirect(http.StatusMovedPermanently, v4.BasePath()+"/highscores/"+c.Param("world")+"/"+c.Param("category")+"/"+TibiaDataDefaultVoc+"/1")
-		})
-		v4.GET("/highscores/:world/:category/:vocation", tibiaHighscores)
-		v4.GET("/highscores/:world/:category/:vocation/:page", tibiaHighscores)
-
-		// Tibia houses
-		v4.GET("/house/:world/:house_id", tibiaHousesHouse)
-		v4.GET("/houses/:world/:town", tibiaHousesOverview)
-
-		// Tibia killstatistics
-		v4.GET("/killstatistics/:world", tibiaKillstatistics)
-
-		// Tibia news
-		v4.GET("/news/archive", tibiaNewslist)       // all categories (default 90 days)
-		v4.GET("/news/archive/:days", tibiaNewslist) // all categories
-		v4.GET("/news/id/:news_id", tibiaNews)       // shows one news entry
-		v4.GET("/news/latest", tibiaNewslist)        // only news and articles
-		v4.GET("/news/newsticker", tibiaNewslist)    // only news_ticker
-
-		// Tibia spells
-		v4.GET("/spell/:spell_id", tibiaSpellsSpell)
-		v4.GET("/spells", tibiaSpellsOverview)
-
-		// Tibia worlds
-		v4.GET("/world/:name", tibiaWorldsWorld)
-		v4.GET("/worlds", tibiaWorldsOverview)
-	}
-
-	// Container version details endpoint
-	router.GET("/versions", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"release": TibiaDataBuildRelease,
-			"build":   TibiaDataBuildBuilder,
-			"commit":  TibiaDataBuildCommit,
-			"edition": TibiaDataBuildEdition,
-		})
-	})
-
-	// Build the http server
-	server := &http.Server{
-		Addr:    ":8080", // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
-		Handler: router,
+	// Setting up the router with all routes
+	router, err := NewRouter(RouterOptions{Config: config})
+	if err != nil {
+		log.Fatalf("[error] TibiaData API router: %s", err)
 	}
 
-	// Prepare for a graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	// Build the listener (server.addr or server.unix_socket, TLS if server.tls_cert_file is set) and the http server
+	listener, err := newListener(config.Server)
+	if err != nil {
+		log.Fatalf("[error] TibiaData API listener: %s", err)
+	}
+	server := newHTTPServer(router, config.Server)
 
-	// Run a go routine that will receive the shutdown input
+	// Background jobs (proxy health checks and cache warmer) are stopped and awaited on shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
 	go func() {
-		<-quit
-		log.Println("[info] TibiaData API received shutdown input")
-		if err := server.Close(); err != nil {
-			log.Fatal("[error] TibiaData API server close error:", err)
-		}
+		router.Run(jobsCtx)
+		close(jobsDone)
 	}()
 
-	// setting readyz endpoint to true
-	isReady.Store(true)
+	// Prepare for a graceful shutdown on SIGINT and SIGTERM (sent by kubernetes)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	log.Println("[info] TibiaData API starting webserver")
+	log.Printf("[info] TibiaData API starting webserver on %s", listener.Addr())
 
-	// Run the server
-	if err := server.ListenAndServe(); err != nil {
-		if err == http.ErrServerClosed {
-			log.Println("[info] TibiaData API server gracefully shut down")
-		} else {
-			log.Fatal("[error] TibiaData API server closed unexpectedly")
-		}
+	// Run the server until the shutdown input, requests in flight are drained afterwards
+	err = serveUntilDone(
+		ctx,
+		server,
+		listener,
+		router.SetReady,
+		time.Duration(config.Server.ShutdownDelaySeconds)*time.Second,
+		time.Duration(config.Server.ShutdownTimeoutSeconds)*time.Second,
+	)
+
+	// Stopping the background jobs and waiting for them
+	stopJobs()
+	<-jobsDone
+
+	if err != nil {
+		log.Fatalf("[error] TibiaData API server closed unexpectedly: %s", err)
 	}
+	log.Println("[info] TibiaData API server gracefully shut down")
 }
 
 // BoostableBosses godoc
@@ -256,13 +256,13 @@ func runWebServer() {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/boostablebosses [get]
-func tibiaBoostableBosses(c *gin.Context) {
+func (s *webServer) tibiaBoostableBosses(c *gin.Context) {
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
 		URL:    "https://www.tibia.com/library/?subtopic=boostablebosses",
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -283,7 +283,7 @@ func tibiaBoostableBosses(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/character/{name} [get]
-func tibiaCharactersCharacter(c *gin.Context) {
+func (s *webServer) tibiaCharactersCharacter(c *gin.Context) {
 	// Getting params from URL
 	name := c.Param("name")
 
@@ -294,14 +294,21 @@ func tibiaCharactersCharacter(c *gin.Context) {
 		return
 	}
 
+	// Encode the name in the charset of tibia.com
+	escapedName, err := s.charset().QueryEscape(name)
+	if err != nil {
+		TibiaDataErrorHandler(c, err, http.StatusBadRequest)
+		return
+	}
+
 	// Build the request structure
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=characters&name=" + TibiaDataQueryEscapeString(name),
+		URL:    "https://www.tibia.com/community/?subtopic=characters&name=" + escapedName,
 	}
 
 	// Handle the request
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -321,13 +328,13 @@ func tibiaCharactersCharacter(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/creatures [get]
-func tibiaCreaturesOverview(c *gin.Context) {
+func (s *webServer) tibiaCreaturesOverview(c *gin.Context) {
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
 		URL:    "https://www.tibia.com/library/?subtopic=creatures",
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -348,12 +355,12 @@ func tibiaCreaturesOverview(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/creature/{race} [get]
-func tibiaCreaturesCreature(c *gin.Context) {
+func (s *webServer) tibiaCreaturesCreature(c *gin.Context) {
 	// getting params from URL
 	race := c.Param("race")
 
 	// Validate the race
-	endpoint, err := validation.IsCreatureNameValid(race)
+	endpoint, err := s.validation().IsCreatureNameValid(race)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -364,7 +371,7 @@ func tibiaCreaturesCreature(c *gin.Context) {
 		URL:    "https://www.tibia.com/library/?subtopic=creatures&race=" + endpoint,
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -384,13 +391,13 @@ func tibiaCreaturesCreature(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/fansites [get]
-func tibiaFansites(c *gin.Context) {
+func (s *webServer) tibiaFansites(c *gin.Context) {
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
 		URL:    "https://www.tibia.com/community/?subtopic=fansites",
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -411,7 +418,7 @@ func tibiaFansites(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/guild/{name} [get]
-func tibiaGuildsGuild(c *gin.Context) {
+func (s *webServer) tibiaGuildsGuild(c *gin.Context) {
 	// getting params from URL
 	guild := c.Param("name")
 
@@ -422,12 +429,19 @@ func tibiaGuildsGuild(c *gin.Context) {
 		return
 	}
 
+	// Encode the name in the charset of tibia.com
+	escapedGuild, err := s.charset().QueryEscape(guild)
+	if err != nil {
+		TibiaDataErrorHandler(c, err, http.StatusBadRequest)
+		return
+	}
+
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=guilds&page=view&GuildName=" + TibiaDataQueryEscapeString(guild),
+		URL:    "https://www.tibia.com/community/?subtopic=guilds&page=view&GuildName=" + escapedGuild,
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -448,12 +462,12 @@ func tibiaGuildsGuild(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/guilds/{world} [get]
-func tibiaGuildsOverview(c *gin.Context) {
+func (s *webServer) tibiaGuildsOverview(c *gin.Context) {
 	// getting params from URL
 	world := c.Param("world")
 
 	// Check if world exists
-	exists, err := validation.WorldExists(world)
+	exists, err := s.validation().WorldExists(world)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -469,10 +483,10 @@ func tibiaGuildsOverview(c *gin.Context) {
 
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=guilds&world=" + TibiaDataQueryEscapeString(world),
+		URL:    "https://www.tibia.com/community/?subtopic=guilds&world=" + s.charset().QueryEscapeString(world),
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -496,7 +510,7 @@ func tibiaGuildsOverview(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/highscores/{world}/{category}/{vocation}/{page} [get]
-func tibiaHighscores(c *gin.Context) {
+func (s *webServer) tibiaHighscores(c *gin.Context) {
 	// getting params from URL
 	world := c.Param("world")
 	category := c.Param("category")
@@ -519,7 +533,7 @@ func tibiaHighscores(c *gin.Context) {
 
 	if world != "" {
 		// Check if world exists
-		exists, err := validation.WorldExists(world)
+		exists, err := s.validation().WorldExists(world)
 		if err != nil {
 			TibiaDataErrorHandler(c, err, 0)
 			return
@@ -555,10 +569,10 @@ func tibiaHighscores(c *gin.Context) {
 
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=highscores&world=" + TibiaDataQueryEscapeString(world) + "&category=" + strconv.Itoa(int(highscoreCategory)) + "&profession=" + TibiaDataQueryEscapeString(vocationid) + "&currentpage=" + TibiaDataQueryEscapeString(page),
+		URL:    "https://www.tibia.com/community/?subtopic=highscores&world=" + s.charset().QueryEscapeString(world) + "&category=" + strconv.Itoa(int(highscoreCategory)) + "&profession=" + s.charset().QueryEscapeString(vocationid) + "&currentpage=" + s.charset().QueryEscapeString(page),
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -580,7 +594,7 @@ func tibiaHighscores(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/house/{world}/{house_id} [get]
-func tibiaHousesHouse(c *gin.Context) {
+func (s *webServer) tibiaHousesHouse(c *gin.Context) {
 	// getting params from URL
 	world := c.Param("world")
 	houseidStr := c.Param("house_id")
@@ -595,7 +609,7 @@ func tibiaHousesHouse(c *gin.Context) {
 	world = TibiaDataStringWorldFormatToTitle(world)
 
 	// Check if world exists
-	exists, err := validation.WorldExists(world)
+	exists, err := s.validation().WorldExists(world)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -607,7 +621,7 @@ func tibiaHousesHouse(c *gin.Context) {
 	}
 
 	// check if house exists
-	exists, err = validation.HouseExistsRaw(houseid)
+	exists, err = s.validation().HouseExistsRaw(houseid)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -620,14 +634,14 @@ func tibiaHousesHouse(c *gin.Context) {
 
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=houses&page=view&world=" + TibiaDataQueryEscapeString(world) + "&houseid=" + TibiaDataQueryEscapeString(houseidStr),
+		URL:    "https://www.tibia.com/community/?subtopic=houses&page=view&world=" + s.charset().QueryEscapeString(world) + "&houseid=" + s.charset().QueryEscapeString(houseidStr),
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
-			return TibiaHousesHouseImpl(houseid, BoxContentHTML)
+			return tibiaHousesHouseImpl(s.validation(), houseid, BoxContentHTML)
 		},
 		"TibiaHousesHouse")
 }
@@ -646,7 +660,7 @@ func tibiaHousesHouse(c *gin.Context) {
 // @Failure      503  {object}  Information
 // @Router       /v4/houses/{world}/{town} [get]
 // TODO: This API needs to be refactored somehow to use tibiaDataRequestHandler
-func tibiaHousesOverview(c *gin.Context) {
+func (s *webServer) tibiaHousesOverview(c *gin.Context) {
 	// getting params from URL
 	world := c.Param("world")
 	town := c.Param("town")
@@ -656,7 +670,7 @@ func tibiaHousesOverview(c *gin.Context) {
 	town = TibiaDataStringWorldFormatToTitle(town)
 
 	// Check if world exists
-	exists, err := validation.WorldExists(world)
+	exists, err := s.validation().WorldExists(world)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -668,7 +682,7 @@ func tibiaHousesOverview(c *gin.Context) {
 	}
 
 	// Check if town exists
-	exists, err = validation.TownExists(town)
+	exists, err = s.validation().TownExists(town)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -679,14 +693,13 @@ func tibiaHousesOverview(c *gin.Context) {
 		return
 	}
 
-	jsonData, err := TibiaHousesOverviewImpl(c, world, town, TibiaDataHTMLDataCollector)
-	if err != nil {
-		TibiaDataErrorHandler(c, err, 0)
-		return
-	}
-
-	// return jsonData
-	TibiaDataAPIHandleResponse(c, "TibiaHousesOverview", jsonData)
+	s.tibiaDataResponseHandler(
+		c,
+		"TibiaHousesOverview "+world+" "+town,
+		func(ctx context.Context) (interface{}, error) {
+			return tibiaHousesOverviewImpl(ctx, world, town, s.upstreamFetcher(), s.charset())
+		},
+		"TibiaHousesOverview")
 }
 
 // Killstatistics godoc
@@ -701,7 +714,7 @@ func tibiaHousesOverview(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/killstatistics/{world} [get]
-func tibiaKillstatistics(c *gin.Context) {
+func (s *webServer) tibiaKillstatistics(c *gin.Context) {
 	// getting params from URL
 	world := c.Param("world")
 
@@ -709,7 +722,7 @@ func tibiaKillstatistics(c *gin.Context) {
 	world = TibiaDataStringWorldFormatToTitle(world)
 
 	// Check if world exists
-	exists, err := validation.WorldExists(world)
+	exists, err := s.validation().WorldExists(world)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -722,10 +735,10 @@ func tibiaKillstatistics(c *gin.Context) {
 
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=killstatistics&world=" + TibiaDataQueryEscapeString(world),
+		URL:    "https://www.tibia.com/community/?subtopic=killstatistics&world=" + s.charset().QueryEscapeString(world),
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -794,7 +807,7 @@ func tibiaNewslistLatest() bool {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/news/newsticker [get]
-func tibiaNewslist(c *gin.Context) {
+func (s *webServer) tibiaNewslist(c *gin.Context) {
 	// getting params from URL
 	daysStr := c.Param("days")
 
@@ -853,7 +866,7 @@ func tibiaNewslist(c *gin.Context) {
 		}
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -874,7 +887,7 @@ func tibiaNewslist(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/news/id/{news_id} [get]
-func tibiaNews(c *gin.Context) {
+func (s *webServer) tibiaNews(c *gin.Context) {
 	// getting params from URL
 	newsIDStr := c.Param("news_id")
 
@@ -897,7 +910,7 @@ func tibiaNews(c *gin.Context) {
 		URL:    "https://www.tibia.com/news/?subtopic=newsarchive&id=" + newsIDStr,
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -917,7 +930,7 @@ func tibiaNews(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/spells [get]
-func tibiaSpellsOverview(c *gin.Context) {
+func (s *webServer) tibiaSpellsOverview(c *gin.Context) {
 	// getting params from URL
 	vocation := c.Param("vocation")
 	if vocation == "" {
@@ -942,10 +955,10 @@ func tibiaSpellsOverview(c *gin.Context) {
 
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/library/?subtopic=spells&vocation=" + TibiaDataQueryEscapeString(vocationName),
+		URL:    "https://www.tibia.com/library/?subtopic=spells&vocation=" + s.charset().QueryEscapeString(vocationName),
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -966,11 +979,11 @@ func tibiaSpellsOverview(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/spell/{spell_id} [get]
-func tibiaSpellsSpell(c *gin.Context) {
+func (s *webServer) tibiaSpellsSpell(c *gin.Context) {
 	// getting params from URL
 	spellRaw := c.Param("spell_id")
 
-	spell, err := validation.IsSpellNameOrFormulaValid(spellRaw)
+	spell, err := s.validation().IsSpellNameOrFormulaValid(spellRaw)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -981,7 +994,7 @@ func tibiaSpellsSpell(c *gin.Context) {
 		URL:    "https://www.tibia.com/library/?subtopic=spells&spell=" + spell,
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -1001,13 +1014,13 @@ func tibiaSpellsSpell(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/worlds [get]
-func tibiaWorldsOverview(c *gin.Context) {
+func (s *webServer) tibiaWorldsOverview(c *gin.Context) {
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
 		URL:    "https://www.tibia.com/community/?subtopic=worlds",
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -1028,7 +1041,7 @@ func tibiaWorldsOverview(c *gin.Context) {
 // @Failure      404  {object}  Information
 // @Failure      503  {object}  Information
 // @Router       /v4/world/{name} [get]
-func tibiaWorldsWorld(c *gin.Context) {
+func (s *webServer) tibiaWorldsWorld(c *gin.Context) {
 	// getting params from URL
 	world := c.Param("name")
 
@@ -1036,7 +1049,7 @@ func tibiaWorldsWorld(c *gin.Context) {
 	world = TibiaDataStringWorldFormatToTitle(world)
 
 	// Check if world exists
-	exists, err := validation.WorldExists(world)
+	exists, err := s.validation().WorldExists(world)
 	if err != nil {
 		TibiaDataErrorHandler(c, err, 0)
 		return
@@ -1049,10 +1062,10 @@ func tibiaWorldsWorld(c *gin.Context) {
 
 	tibiadataRequest := TibiaDataRequestStruct{
 		Method: resty.MethodGet,
-		URL:    "https://www.tibia.com/community/?subtopic=worlds&world=" + TibiaDataQueryEscapeString(world),
+		URL:    "https://www.tibia.com/community/?subtopic=worlds&world=" + s.charset().QueryEscapeString(world),
 	}
 
-	tibiaDataRequestHandler(
+	s.tibiaDataRequestHandler(
 		c,
 		tibiadataRequest,
 		func(BoxContentHTML string) (interface{}, error) {
@@ -1066,6 +1079,16 @@ func TibiaDataErrorHandler(c *gin.Context, err error, httpCode int) {
 		panic(errors.New("TibiaDataErrorHandler called with nil err"))
 	}
 
+	// errors after which the client should retry later are sent as 503
+	var retryAfterErr tibiaDataRetryAfterError
+	if errors.As(err, &retryAfterErr) {
+		httpCode = http.StatusServiceUnavailable
+		if retryAfterErr.retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfterErr.retryAfter.Seconds()))))
+		}
+		err = retryAfterErr.err
+	}
+
 	info := Information{
 		APIDetails: TibiaDataAPIDetails,
 		Timestamp:  TibiaDataDatetime(""),
@@ -1073,6 +1096,7 @@ func TibiaDataErrorHandler(c *gin.Context, err error, httpCode int) {
 			HTTPCode: httpCode,
 		},
 	}
+	webServerOf(c).serverSaveSchedule().apply(&info)
 
 	switch t := err.(type) {
 	case validation.Error:
@@ -1102,166 +1126,231 @@ func TibiaDataErrorHandler(c *gin.Context, err error, httpCode int) {
 	var output OutInformation
 	output.Information = info
 
+	// errors must not be kept by caches in front of the API
+	c.Header("Cache-Control", "no-store")
 	c.JSON(httpCode, output)
 }
 
-func tibiaDataRequestHandler(c *gin.Context, tibiaDataRequest TibiaDataRequestStruct, requestHandler func(string) (interface{}, error), handlerName string) {
-	BoxContentHTML, err := TibiaDataHTMLDataCollector(tibiaDataRequest)
-	// return error (e.g. for maintenance mode)
-	if err != nil {
-		TibiaDataErrorHandler(c, err, http.StatusBadGateway)
-		return
+// upstreamErrorStatus func - returns the http status code for an error of the fetcher
+func upstreamErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		// the deadline budget was used up before tibia.com answered
+		return http.StatusGatewayTimeout
+	case errors.Is(err, validation.ErrorUpstreamPageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, validation.ErrorUpstreamThrottled):
+		// a captcha page, the client can try again later
+		return http.StatusServiceUnavailable
+	case errors.Is(err, validation.ErrorUpstreamUnknownLayout):
+		// tibia.com answered, but the API can not parse the page
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadGateway
 	}
+}
 
-	jsonData, err := requestHandler(BoxContentHTML)
-	if err != nil {
-		TibiaDataErrorHandler(c, err, 0)
-		return
-	}
+// tibiaDataUpstreamError wraps errors returned by the fetcher
+// so they can be told apart from errors of the request handler
+type tibiaDataUpstreamError struct {
+	err error
+}
 
-	// return jsonData
-	TibiaDataAPIHandleResponse(c, handlerName, jsonData)
+func (e tibiaDataUpstreamError) Error() string {
+	return e.err.Error()
 }
 
-// TibiaDataAPIHandleResponse func - handling of responses..
-// This should NOT be invoked if an error occured
-func TibiaDataAPIHandleResponse(c *gin.Context, s string, j interface{}) {
-	// print to log about request
-	if gin.IsDebugging() {
-		log.Println("[debug] " + s + " - (" + c.Request.RequestURI + ") returned data:")
-		js, err := json.Marshal(j)
-		log.Printf("[debug] %s\n", js)
-		if err != nil {
-			log.Printf("[debug] the above had an error: %s\n", err)
+func (e tibiaDataUpstreamError) Unwrap() error {
+	return e.err
+}
+
+// upstreamFetcher func - returns the fetcher of s with its errors wrapped in tibiaDataUpstreamError
+// requests pass through the circuit breaker if it is set
+func (s *webServer) upstreamFetcher() Fetcher {
+	return FetcherFunc(func(ctx context.Context, tibiaDataRequest TibiaDataRequestStruct) (string, error) {
+		var probe bool
+		if s.circuit != nil {
+			var err error
+			if probe, err = s.circuit.Allow(); err != nil {
+				return "", tibiaDataUpstreamError{err}
+			}
 		}
-	}
 
-	if TibiaDataDebug {
-		log.Println("[info] " + s + " - (" + c.Request.RequestURI + ") executed successfully.")
-	}
+		BoxContentHTML, err := s.fetcher.Fetch(ctx, tibiaDataRequest)
+		if s.circuit != nil {
+			// only the outcome of requests sent to tibia.com is recorded,
+			// requests given up because the deadline budget of the handler was used up say nothing about it
+			var queueErr tibiaDataQueueError
+			if errors.As(err, &queueErr) || (err != nil && ctx.Err() != nil) {
+				s.circuit.Abandon(probe)
+			} else {
+				err = s.circuit.Record(probe, err)
+			}
+		}
 
-	// return successful response
-	c.JSON(http.StatusOK, j)
+		// return error (e.g. for maintenance mode)
+		if err != nil {
+			return "", tibiaDataUpstreamError{err}
+		}
+
+		return BoxContentHTML, nil
+	})
 }
 
-// TibiadataUserAgentGenerator func - creates User-Agent for requests
-func TibiaDataUserAgentGenerator(version int) string {
-	// setting product name
-	useragent := "TibiaData-API/v" + strconv.Itoa(version)
+func (s *webServer) tibiaDataRequestHandler(c *gin.Context, tibiaDataRequest TibiaDataRequestStruct, requestHandler func(string) (interface{}, error), handlerName string) {
+	s.tibiaDataResponseHandler(
+		c,
+		tibiaDataRequest.Key(),
+		func(ctx context.Context) (interface{}, error) {
+			BoxContentHTML, err := s.upstreamFetcher().Fetch(ctx, tibiaDataRequest)
+			if err != nil {
+				return nil, err
+			}
 
-	// adding details in parenthesis
-	useragentDetails := []string{
-		"release/" + TibiaDataBuildRelease,
-		"build/" + TibiaDataBuildBuilder,
-		"commit/" + TibiaDataBuildCommit,
-		"edition/" + TibiaDataBuildEdition,
-		TibiaDataHost,
-	}
-	useragent += " (" + strings.Join(useragentDetails, "; ") + ")"
+			result, err := requestHandler(BoxContentHTML)
 
-	return useragent
+			// no data in the page is a failure of tibia.com (e.g. a layout change)
+			if errors.Is(err, validation.ErrorUpstreamUnknownLayout) {
+				return nil, tibiaDataUpstreamError{err}
+			}
+
+			return result, err
+		},
+		handlerName)
 }
 
-// TibiaDataHTMLDataCollector func
-func TibiaDataHTMLDataCollector(TibiaDataRequest TibiaDataRequestStruct) (string, error) {
-	// Setting up resty client
-	client := resty.New()
+// tibiaDataResponseHandler serves the response identified by key
+// either from the cache or by running the collector
+// the collector is cancelled once all clients waiting for it are gone or the deadline budget of the handler is used up
+func (s *webServer) tibiaDataResponseHandler(c *gin.Context, key string, collector func(ctx context.Context) (interface{}, error), handlerName string) {
+	policy, hasPolicy := TibiaDataCachePolicies[handlerName]
+	cacheable := hasPolicy && s.cache != nil
 
-	// Set Debug if enabled by TibiaDataDebug var
-	if TibiaDataDebug {
-		client.SetDebug(true)
-		client.EnableTrace()
+	clientCtx := context.Background()
+	if c.Request != nil {
+		clientCtx = c.Request.Context()
 	}
 
-	// Set client timeout  and retry
-	client.SetTimeout(5 * time.Second)
-	client.SetRetryCount(2)
+	// the cache warmer replaces the cached response
+	if cacheable && !isCacheRefresh(clientCtx) {
+		if entry, ok := s.cache.Get(key); ok {
+			TibiaDataAPIHandleCachedResponse(c, handlerName, entry, true)
+			return
+		}
+	}
 
-	// Set headers for all requests
-	client.SetHeaders(map[string]string{
-		"Content-Type": "application/json",
-		"User-Agent":   TibiaDataUserAgent,
-	})
+	// tibia.com is offline during the server save, so the last successful response is served without asking it
+	if s.serverSaveSchedule().InProgress() && s.serveStale(c, key, errServerSaveInProgress, handlerName) {
+		return
+	}
 
-	// Enabling Content length value for all request
-	client.SetContentLength(true)
+	// concurrent callers of the same request share one fetch and parse
+	flight := s.joinFlight(key, s.deadlineBudget(handlerName), upstreamPriorityFromContext(clientCtx))
+	resultChan := s.requests.DoChan(key, func() (interface{}, error) {
+		defer s.landFlight(key, flight)
 
-	// Disable redirection of client (so we skip parsing maintenance page)
-	client.SetRedirectPolicy(resty.NoRedirectPolicy())
+		// the upstream requests get the highest priority of the callers waiting for the flight
+		jsonData, err := collector(flight.ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	// Replace domain with proxy if env TIBIADATA_PROXY set
-	if TibiaDataProxyDomain != "" {
-		TibiaDataRequest.URL = strings.ReplaceAll(TibiaDataRequest.URL, "https://www.tibia.com/", TibiaDataProxyDomain)
-	}
+		data, err := json.Marshal(jsonData)
+		if err != nil {
+			return nil, err
+		}
 
-	// defining values for request
-	var (
-		res        *resty.Response
-		err        error
-		LogMessage string
-	)
+		entry := CacheEntry{
+			Data:     data,
+			StoredAt: time.Now(),
+		}
 
-	switch TibiaDataRequest.Method {
-	case resty.MethodPost:
-		res, err = client.R().
-			SetFormData(TibiaDataRequest.FormData).
-			Post(TibiaDataRequest.URL)
-	default:
-		res, err = client.R().Get(TibiaDataRequest.URL)
-	}
+		// the policy decides on the Cache-Control header even if the cache is disabled
+		if hasPolicy {
+			if ttl := policy(jsonData, s.serverSaveSchedule()); ttl > 0 {
+				entry.ExpiresAt = entry.StoredAt.Add(ttl)
+				if cacheable {
+					s.cache.Set(key, entry, ttl)
+				}
+			}
+		}
 
-	if TibiaDataDebug {
-		// logging trace information for resty
-		TibiaDataRequestTraceLogger(res, err)
+		s.storeStale(key, entry)
+
+		return entry, nil
+	})
+
+	var sharedResult singleflight.Result
+	select {
+	case sharedResult = <-resultChan:
+		s.leaveFlight(key, flight)
+	case <-clientCtx.Done():
+		s.leaveFlight(key, flight)
+		log.Printf("[info] TibiaData API %s - client went away before %s finished: %s", handlerName, key, clientCtx.Err())
+		c.Abort()
+		return
 	}
 
+	result, err := sharedResult.Val, sharedResult.Err
 	if err != nil {
-		log.Printf("[error] TibiaDataHTMLDataCollector (Status: %s, URL: %s) in resp1: %s", res.Status(), res.Request.URL, err)
-
-		switch res.StatusCode() {
-		case http.StatusForbidden:
-			// throttled request
-			LogMessage = "request throttled due to rate-limitation on tibia.com"
-			log.Printf("[warning] TibiaDataHTMLDataCollector: %s!", LogMessage)
-			return "", err
-
-		case http.StatusFound:
-			// Check if page is in maintenance mode
-			location, _ := res.RawResponse.Location()
-			if location.Host == "maintenance.tibia.com" {
-				LogMessage := "maintenance mode detected on tibia.com"
-				log.Printf("[info] TibiaDataHTMLDataCollector: %s!", LogMessage)
-				return "", validation.ErrorMaintenanceMode
+		var upstreamErr tibiaDataUpstreamError
+		if errors.As(err, &upstreamErr) {
+			// serve the last successful response if there is one (e.g. for maintenance mode)
+			if s.staleIfError && s.serveStale(c, key, upstreamErr.err, handlerName) {
+				return
 			}
-			fallthrough
 
-		default:
-			LogMessage = "unknown error occurred on tibia.com"
-			log.Printf("[error] TibiaDataHTMLDataCollector: %s!", LogMessage)
-			return "", err
+			TibiaDataErrorHandler(c, upstreamErr.err, upstreamErrorStatus(upstreamErr.err))
+			return
 		}
+
+		TibiaDataErrorHandler(c, err, 0)
+		return
 	}
 
-	// Convert body to io.Reader
-	resIo := bytes.NewReader(res.Body())
+	entry := result.(CacheEntry)
+	if !cacheable {
+		TibiaDataSetCacheHeaders(c, entry, time.Now())
+		tibiaDataWriteSerializedResponse(c, handlerName, entry.withInformation(s.serverSaveSchedule().apply))
+		return
+	}
 
-	// wrap reader in a converting reader from ISO 8859-1 to UTF-8
-	resIo2 := TibiaDataConvertEncodingtoUTF8(resIo)
+	TibiaDataAPIHandleCachedResponse(c, handlerName, entry, false)
+}
 
-	// Load the HTML document
-	doc, err := goquery.NewDocumentFromReader(resIo2)
+// TibiaDataAPIHandleResponse func - handling of responses..
+// This should NOT be invoked if an error occured
+func TibiaDataAPIHandleResponse(c *gin.Context, s string, j interface{}) {
+	data, err := json.Marshal(j)
 	if err != nil {
-		log.Printf("[error] TibiaDataHTMLDataCollector (URL: %s) error: %s", res.Request.URL, err)
+		TibiaDataErrorHandler(c, err, http.StatusInternalServerError)
+		return
 	}
 
-	// Find of this to get div with class BoxContent
-	data, err := doc.Find(".Border_2 .Border_3").Html()
-	if err != nil {
-		return "", err
+	if webServerOf(c).debugMode().Load() {
+		log.Println("[info] " + s + " - (" + c.Request.RequestURI + ") executed successfully.")
 	}
 
-	// Return of extracted html to functions..
-	return data, nil
+	// return successful response
+	tibiaDataWriteSerializedResponse(c, s, data)
+}
+
+// TibiadataUserAgentGenerator func - creates User-Agent for requests
+func TibiaDataUserAgentGenerator(version int) string {
+	// setting product name
+	useragent := "TibiaData-API/v" + strconv.Itoa(version)
+
+	// adding details in parenthesis
+	useragentDetails := []string{
+		"release/" + TibiaDataBuildRelease,
+		"build/" + TibiaDataBuildBuilder,
+		"commit/" + TibiaDataBuildCommit,
+		"edition/" + TibiaDataBuildEdition,
+		TibiaDataHost,
+	}
+	useragent += " (" + strings.Join(useragentDetails, "; ") + ")"
+
+	return useragent
 }
 
 // healthz is a k8s liveness probe
@@ -1270,10 +1359,17 @@ func healthz(c *gin.Context) {
 }
 
 // readyz is a k8s readiness probe
-func readyz(c *gin.Context) {
-	if isReady == nil || !isReady.Load().(bool) {
+// the state of tibia.com is reported but does not affect readiness (cached responses can still be served)
+func (s *webServer) readyz(c *gin.Context) {
+	if !s.ready.Load() {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": http.StatusText(http.StatusServiceUnavailable)})
 		return
 	}
-	TibiaDataAPIHandleResponse(c, "readyz", gin.H{"status": http.StatusText(http.StatusOK)})
+
+	response := gin.H{"status": http.StatusText(http.StatusOK)}
+	if s.circuit != nil {
+		response["upstream"] = s.circuit.Status()
+	}
+
+	TibiaDataAPIHandleResponse(c, "readyz", response)
 }
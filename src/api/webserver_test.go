@@ -0,0 +1,420 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/faketibia"
+	"github.com/TibiaData/tibiadata-api-go/src/static"
+	"github.com/TibiaData/tibiadata-api-go/src/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = func() bool {
+	testing.Init()
+	return true
+}()
+
+func TestFakeToUpCodeCoverage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the requests go to faketibia, which serves the static test files like tibia.com
+	fake, err := faketibia.New(fakeTibiaTestFiles(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := &webServer{
+		fetcher: newTibiaDataFetcher(server.URL+"/", 16),
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "name",
+			Value: "Riley No Hands",
+		},
+	}
+
+	assert := assert.New(t)
+
+	s.tibiaBoostableBosses(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	s.tibiaCharactersCharacter(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	s.tibiaCreaturesOverview(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "race",
+			Value: "demon",
+		},
+	}
+
+	s.tibiaCreaturesCreature(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	s.tibiaFansites(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "name",
+			Value: "Elysium",
+		},
+	}
+
+	s.tibiaGuildsGuild(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "world",
+			Value: "premia",
+		},
+	}
+
+	s.tibiaGuildsOverview(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "world",
+			Value: "all",
+		},
+		{
+			Key:   "category",
+			Value: "experience",
+		},
+		{
+			Key:   "vocation",
+			Value: "all",
+		},
+	}
+
+	s.tibiaHighscores(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "world",
+			Value: "premia",
+		},
+		{
+			Key:   "house_id",
+			Value: "54025",
+		},
+	}
+
+	s.tibiaHousesHouse(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "world",
+			Value: "antica",
+		},
+		{
+			Key:   "town",
+			Value: "thais",
+		},
+	}
+
+	s.tibiaHousesOverview(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "world",
+			Value: "antica",
+		},
+	}
+
+	s.tibiaKillstatistics(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	assert.False(false, tibiaNewslistArchive())
+	assert.False(false, tibiaNewslistArchiveDays())
+	assert.False(false, tibiaNewslistLatest())
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "days",
+			Value: "90",
+		},
+	}
+
+	s.tibiaNewslist(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "news_id",
+			Value: "6529",
+		},
+	}
+
+	s.tibiaNews(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "vocation",
+			Value: "druid",
+		},
+	}
+
+	s.tibiaSpellsOverview(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "spell_id",
+			Value: "exori gran ico",
+		},
+	}
+
+	s.tibiaSpellsSpell(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	s.tibiaWorldsOverview(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{
+		{
+			Key:   "name",
+			Value: "premia",
+		},
+	}
+
+	s.tibiaWorldsWorld(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	assert.Equal("TibiaData-API/v4 (release/unknown; build/manual; commit/-; edition/open-source; unittest.example.com)", TibiaDataUserAgentGenerator(TibiaDataAPIversion))
+
+	healthz(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	s.ready.Store(true)
+	s.readyz(c)
+	assert.Equal(http.StatusOK, w.Code)
+
+	type test struct {
+		T string `json:"t"`
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+
+	TibiaDataAPIHandleResponse(c, "", test{T: "abc"})
+	assert.Equal(http.StatusOK, w.Code)
+}
+
+// fakeTibiaTestFiles func - returns the static test files with the news list of the last 90 days
+// the news list of the period requested by tibiaNewslist is served with the test file of an older period
+func fakeTibiaTestFiles(t *testing.T) fs.FS {
+	files, err := fs.Sub(static.TestFiles, "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFiles := fstest.MapFS{}
+	err = fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(files, name)
+		testFiles[name] = &fstest.MapFile{Data: data}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	begin, end := time.Now().AddDate(0, 0, -90).UTC(), time.Now().UTC()
+	form := url.Values{
+		"filter_begin_day":   {strconv.Itoa(begin.Day())},
+		"filter_begin_month": {strconv.Itoa(int(begin.Month()))},
+		"filter_begin_year":  {strconv.Itoa(begin.Year())},
+		"filter_end_day":     {strconv.Itoa(end.Day())},
+		"filter_end_month":   {strconv.Itoa(int(end.Month()))},
+		"filter_end_year":    {strconv.Itoa(end.Year())},
+		"filter_cipsoft":     {"cipsoft"},
+		"filter_community":   {"community"},
+		"filter_development": {"development"},
+		"filter_support":     {"support"},
+		"filter_technical":   {"technical"},
+	}
+	name := static.TestFilePath(url.Values{"subtopic": {"newsarchive"}}, form) + ".html"
+	testFiles[name] = testFiles["news/newslist.html"]
+
+	return testFiles
+}
+
+func TestErrorHandler(t *testing.T) {
+	assert := assert.New(t)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	TibiaDataErrorHandler(c, errors.New("test error"), http.StatusBadRequest)
+	assert.Equal(http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	TibiaDataErrorHandler(c, validation.ErrorAlreadyRunning, 0)
+	assert.Equal(http.StatusInternalServerError, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	TibiaDataErrorHandler(c, validation.ErrorCharacterNameInvalid, 0)
+	assert.Equal(http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	TibiaDataErrorHandler(c, errors.New("test error"), 0)
+	assert.Equal(http.StatusBadGateway, w.Code)
+}
+
+func TestTibiaDataRequestKey(t *testing.T) {
+	assert := assert.New(t)
+
+	get := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+	assert.Equal("GET https://www.tibia.com/community/?subtopic=worlds", get.Key())
+
+	a := TibiaDataRequestStruct{
+		Method:   http.MethodPost,
+		URL:      "https://www.tibia.com/news/?subtopic=newsarchive",
+		FormData: map[string]string{"filter_news": "news", "filter_article": "article", "filter_ticker": "ticker"},
+	}
+	b := TibiaDataRequestStruct{
+		Method:   http.MethodPost,
+		URL:      "https://www.tibia.com/news/?subtopic=newsarchive",
+		FormData: map[string]string{"filter_ticker": "ticker", "filter_article": "article", "filter_news": "news"},
+	}
+	assert.Equal(a.Key(), b.Key())
+	assert.Equal("POST https://www.tibia.com/news/?subtopic=newsarchive&filter_article=article&filter_news=news&filter_ticker=ticker", a.Key())
+
+	b.FormData["filter_ticker"] = ""
+	assert.NotEqual(a.Key(), b.Key())
+}
+
+func TestRequestCoalescing(t *testing.T) {
+	assert := assert.New(t)
+
+	var fetches, parses int32
+	release := make(chan struct{})
+
+	s := &webServer{
+		fetcher: FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+			atomic.AddInt32(&fetches, 1)
+			<-release
+			return "content", nil
+		}),
+	}
+
+	request := TibiaDataRequestStruct{URL: "https://www.tibia.com/community/?subtopic=worlds"}
+
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, 10)
+	for i := range recorders {
+		recorders[i] = httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorders[i])
+		c.Request = httptest.NewRequest(http.MethodGet, "/v4/worlds", nil)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.tibiaDataRequestHandler(c, request, func(BoxContentHTML string) (interface{}, error) {
+				atomic.AddInt32(&parses, 1)
+				return gin.H{"data": BoxContentHTML}, nil
+			}, "TestRequestCoalescing")
+		}()
+	}
+
+	// give all callers time to join the in-flight request
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(1, atomic.LoadInt32(&fetches))
+	assert.EqualValues(1, atomic.LoadInt32(&parses))
+	for _, w := range recorders {
+		assert.Equal(http.StatusOK, w.Code)
+		assert.JSONEq(`{"data":"content"}`, w.Body.String())
+	}
+
+	// upstream errors are still returned as bad gateway
+	s.fetcher = FetcherFunc(func(ctx context.Context, request TibiaDataRequestStruct) (string, error) {
+		return "", validation.ErrorMaintenanceMode
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	s.tibiaDataRequestHandler(c, request, func(BoxContentHTML string) (interface{}, error) {
+		return nil, nil
+	}, "TestRequestCoalescing")
+	assert.Equal(http.StatusBadGateway, w.Code)
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// upstreamPriority is the priority class of a request to tibia.com
+type upstreamPriority int
+
+const (
+	// upstreamPriorityInteractive is used for requests of API clients
+	upstreamPriorityInteractive upstreamPriority = iota
+	// upstreamPriorityBackground is used for requests of cache warmers and other background jobs
+	upstreamPriorityBackground
+
+	upstreamPriorities = 2
+)
+
+func (p upstreamPriority) String() string {
+	switch p {
+	case upstreamPriorityBackground:
+		return "background"
+	default:
+		return "interactive"
+	}
+}
+
+type upstreamPriorityKey struct{}
+
+// withUpstreamPriority func - returns a copy of ctx whose requests to tibia.com use priority
+func withUpstreamPriority(ctx context.Context, priority upstreamPriority) context.Context {
+	return context.WithValue(ctx, upstreamPriorityKey{}, priority)
+}
+
+// withSharedUpstreamPriority func - returns a copy of ctx whose requests to tibia.com use the priority of shared
+// the requests waiting for a worker move up once shared is raised
+func withSharedUpstreamPriority(ctx context.Context, shared *sharedUpstreamPriority) context.Context {
+	return context.WithValue(ctx, upstreamPriorityKey{}, shared)
+}
+
+// upstreamPriorityFromContext func - returns the priority of ctx (interactive if not set)
+func upstreamPriorityFromContext(ctx context.Context) upstreamPriority {
+	priority, _ := watchUpstreamPriority(ctx)
+
+	return priority
+}
+
+// watchUpstreamPriority func - returns the priority of ctx and a channel closed once it is raised
+// the channel is nil if the priority of ctx can not change
+func watchUpstreamPriority(ctx context.Context) (upstreamPriority, <-chan struct{}) {
+	switch priority := ctx.Value(upstreamPriorityKey{}).(type) {
+	case upstreamPriority:
+		return priority, nil
+	case *sharedUpstreamPriority:
+		return priority.watch()
+	}
+
+	return upstreamPriorityInteractive, nil
+}
+
+// sharedUpstreamPriority is the priority of a request shared by several callers (e.g. coalesced requests)
+// it is the highest priority of the callers, so it is raised when a caller with a higher priority joins
+type sharedUpstreamPriority struct {
+	mu       sync.Mutex
+	priority upstreamPriority
+	raised   chan struct{} // closed once the priority is raised
+}
+
+// newSharedUpstreamPriority func - creates a sharedUpstreamPriority starting at priority
+func newSharedUpstreamPriority(priority upstreamPriority) *sharedUpstreamPriority {
+	return &sharedUpstreamPriority{
+		priority: priority,
+		raised:   make(chan struct{}),
+	}
+}
+
+// Raise func - raises the priority to priority unless it already is higher
+func (p *sharedUpstreamPriority) Raise(priority upstreamPriority) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if priority >= p.priority {
+		return
+	}
+
+	p.priority = priority
+	close(p.raised)
+	p.raised = make(chan struct{})
+}
+
+// watch func - returns the priority and a channel closed once it is raised
+func (p *sharedUpstreamPriority) watch() (upstreamPriority, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.priority, p.raised
+}
+
+// UpstreamWorkersStatus is the state of the upstream worker pool shown on /debug
+type UpstreamWorkersStatus struct {
+	Workers  int                   `json:"workers"`  // the concurrency cap
+	Reserved int                   `json:"reserved"` // the workers only used by interactive requests
+	Busy     int                   `json:"busy"`     // requests currently sent to tibia.com
+	Queues   []UpstreamQueueStatus `json:"queues"`   // one queue per priority class
+}
+
+// UpstreamQueueStatus is the state of the queue of a priority class
+type UpstreamQueueStatus struct {
+	Priority string  `json:"priority"`
+	Depth    int     `json:"depth"`       // requests currently waiting for a worker
+	MaxDepth int     `json:"max_depth"`   // the highest depth seen
+	Requests uint64  `json:"requests"`    // requests that got a worker
+	AvgWait  float64 `json:"avg_wait_ms"` // average time waited for a worker
+}
+
+// upstreamWaiter is a request waiting for a worker
+type upstreamWaiter struct {
+	priority upstreamPriority // the queue of the waiter, the worker is handed over for this priority
+	ready    chan struct{}    // closed when the worker is handed over
+}
+
+// upstreamQueue holds the waiting requests of a priority class
+type upstreamQueue struct {
+	waiters  []*upstreamWaiter
+	maxDepth int
+	requests uint64
+	waited   time.Duration
+}
+
+// upstreamWorkerPool bounds the number of concurrent requests to tibia.com
+// waiting requests get a worker by priority class, so API clients never wait behind background jobs
+// a share of the workers is reserved for interactive requests, so background jobs never hold all of them
+type upstreamWorkerPool struct {
+	mu       sync.Mutex
+	workers  int
+	reserved int                     // workers only used by interactive requests
+	busy     [upstreamPriorities]int // workers held by the requests of each priority class
+	queues   [upstreamPriorities]upstreamQueue
+}
+
+// newUpstreamWorkerPool func - creates an upstreamWorkerPool with workers concurrent requests
+// reserved of them are only used by interactive requests (at least one worker is left for the other requests)
+func newUpstreamWorkerPool(workers, reserved int) *upstreamWorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if reserved > workers-1 {
+		reserved = workers - 1
+	}
+	if reserved < 0 {
+		reserved = 0
+	}
+
+	return &upstreamWorkerPool{workers: workers, reserved: reserved}
+}
+
+// Acquire func - blocks until a worker is free for a request with the priority of ctx
+// the request moves to the queue of a higher priority if the priority of ctx is raised meanwhile
+// returns the func releasing the worker or the error of ctx if it is done before
+func (p *upstreamWorkerPool) Acquire(ctx context.Context) (func(), error) {
+	priority, raised := watchUpstreamPriority(ctx)
+	start := time.Now()
+
+	p.mu.Lock()
+	if p.waiting(priority) == 0 && p.free(priority) {
+		p.take(priority)
+		p.mu.Unlock()
+		return p.releaser(priority), nil
+	}
+
+	waiter := &upstreamWaiter{priority: priority, ready: make(chan struct{})}
+	p.enqueue(waiter)
+	p.mu.Unlock()
+
+	for {
+		select {
+		case <-waiter.ready:
+			p.mu.Lock()
+			p.queues[waiter.priority].waited += time.Since(start)
+			p.mu.Unlock()
+			return p.releaser(waiter.priority), nil
+
+		case <-raised:
+			priority, raised = watchUpstreamPriority(ctx)
+
+			p.mu.Lock()
+			if priority < waiter.priority && p.dequeue(waiter) {
+				waiter.priority = priority
+				p.enqueue(waiter)
+				p.dispatch()
+			}
+			p.mu.Unlock()
+
+		case <-ctx.Done():
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			if p.dequeue(waiter) {
+				return nil, ctx.Err()
+			}
+
+			// the worker was handed over at the same time, so it is passed on
+			p.release(waiter.priority)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// releaser func - returns the func releasing a worker of priority once
+func (p *upstreamWorkerPool) releaser(priority upstreamPriority) func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			p.release(priority)
+		})
+	}
+}
+
+// release func - frees a worker of priority and hands it over to the next waiting request, the lock must be held
+func (p *upstreamWorkerPool) release(priority upstreamPriority) {
+	p.busy[priority]--
+	p.dispatch()
+}
+
+// dispatch func - hands the free workers over to the waiting requests by priority, the lock must be held
+func (p *upstreamWorkerPool) dispatch() {
+	for i := range p.queues {
+		priority := upstreamPriority(i)
+		queue := &p.queues[i]
+
+		for len(queue.waiters) > 0 && p.free(priority) {
+			waiter := queue.waiters[0]
+			queue.waiters = queue.waiters[1:]
+			p.take(priority)
+			close(waiter.ready)
+		}
+	}
+}
+
+// free func - reports whether a worker is free for a request of priority, the lock must be held
+// background requests leave the reserved workers to interactive requests
+func (p *upstreamWorkerPool) free(priority upstreamPriority) bool {
+	busy := 0
+	for i := range p.busy {
+		busy += p.busy[i]
+	}
+
+	if priority == upstreamPriorityInteractive {
+		return busy < p.workers
+	}
+
+	return busy < p.workers && busy-p.busy[upstreamPriorityInteractive] < p.workers-p.reserved
+}
+
+// take func - marks a worker as held by a request of priority, the lock must be held
+func (p *upstreamWorkerPool) take(priority upstreamPriority) {
+	p.busy[priority]++
+	p.queues[priority].requests++
+}
+
+// enqueue func - adds waiter to the queue of its priority, the lock must be held
+func (p *upstreamWorkerPool) enqueue(waiter *upstreamWaiter) {
+	queue := &p.queues[waiter.priority]
+	queue.waiters = append(queue.waiters, waiter)
+	if len(queue.waiters) > queue.maxDepth {
+		queue.maxDepth = len(queue.waiters)
+	}
+}
+
+// dequeue func - removes waiter from the queue of its priority, the lock must be held
+// returns false if it got a worker already
+func (p *upstreamWorkerPool) dequeue(waiter *upstreamWaiter) bool {
+	queue := &p.queues[waiter.priority]
+	for i, w := range queue.waiters {
+		if w == waiter {
+			queue.waiters = append(queue.waiters[:i], queue.waiters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// waiting func - returns the number of requests waiting with priority or a higher one, the lock must be held
+func (p *upstreamWorkerPool) waiting(priority upstreamPriority) int {
+	waiting := 0
+	for i := 0; i <= int(priority); i++ {
+		waiting += len(p.queues[i].waiters)
+	}
+
+	return waiting
+}
+
+// Status func - returns the state of the pool and its queues
+func (p *upstreamWorkerPool) Status() UpstreamWorkersStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := UpstreamWorkersStatus{
+		Workers:  p.workers,
+		Reserved: p.reserved,
+	}
+	for i := range p.busy {
+		status.Busy += p.busy[i]
+	}
+
+	for i := range p.queues {
+		queue := &p.queues[i]
+
+		var avgWait float64
+		if queue.requests > 0 {
+			avgWait = float64(queue.waited.Milliseconds()) / float64(queue.requests)
+		}
+
+		status.Queues = append(status.Queues, UpstreamQueueStatus{
+			Priority: upstreamPriority(i).String(),
+			Depth:    len(queue.waiters),
+			MaxDepth: queue.maxDepth,
+			Requests: queue.requests,
+			AvgWait:  avgWait,
+		})
+	}
+
+	return status
+}
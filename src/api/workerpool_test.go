@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamWorkerPoolPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	pool := newUpstreamWorkerPool(1, 0)
+	release, err := pool.Acquire(context.Background())
+	assert.Nil(err)
+
+	// a background request queues before an interactive one
+	order := make(chan upstreamPriority, 2)
+	acquire := func(priority upstreamPriority) {
+		ctx := withUpstreamPriority(context.Background(), priority)
+		if release, err := pool.Acquire(ctx); err == nil {
+			order <- priority
+			release()
+		}
+	}
+
+	go acquire(upstreamPriorityBackground)
+	assert.Eventually(func() bool { return pool.Status().Queues[upstreamPriorityBackground].Depth == 1 }, time.Second, time.Millisecond)
+	go acquire(upstreamPriorityInteractive)
+	assert.Eventually(func() bool { return pool.Status().Queues[upstreamPriorityInteractive].Depth == 1 }, time.Second, time.Millisecond)
+
+	status := pool.Status()
+	assert.Equal(1, status.Workers)
+	assert.Equal(1, status.Busy)
+
+	// the interactive request gets the worker first
+	release()
+	assert.Equal(upstreamPriorityInteractive, <-order)
+	assert.Equal(upstreamPriorityBackground, <-order)
+
+	status = pool.Status()
+	assert.Equal(0, status.Busy)
+	assert.Equal("interactive", status.Queues[upstreamPriorityInteractive].Priority)
+	assert.Equal(uint64(2), status.Queues[upstreamPriorityInteractive].Requests)
+	assert.Equal(1, status.Queues[upstreamPriorityBackground].MaxDepth)
+	assert.Equal(0, status.Queues[upstreamPriorityBackground].Depth)
+}
+
+func TestUpstreamWorkerPoolCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	pool := newUpstreamWorkerPool(1, 0)
+	release, err := pool.Acquire(context.Background())
+	assert.Nil(err)
+
+	// waiting requests leave the queue when their context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(0, pool.Status().Queues[upstreamPriorityInteractive].Depth)
+
+	// releasing twice frees the worker once
+	release()
+	release()
+	assert.Equal(0, pool.Status().Busy)
+	_, err = pool.Acquire(context.Background())
+	assert.Nil(err)
+}
+
+func TestUpstreamWorkerPoolReserved(t *testing.T) {
+	assert := assert.New(t)
+
+	pool := newUpstreamWorkerPool(3, 1)
+	assert.Equal(1, pool.Status().Reserved)
+
+	background := withUpstreamPriority(context.Background(), upstreamPriorityBackground)
+	releaseFirst, err := pool.Acquire(background)
+	assert.Nil(err)
+	_, err = pool.Acquire(background)
+	assert.Nil(err)
+
+	// the last worker is reserved for interactive requests
+	acquired := make(chan func(), 1)
+	go func() {
+		if release, err := pool.Acquire(background); err == nil {
+			acquired <- release
+		}
+	}()
+	assert.Eventually(func() bool { return pool.Status().Queues[upstreamPriorityBackground].Depth == 1 }, time.Second, time.Millisecond)
+
+	releaseInteractive, err := pool.Acquire(context.Background())
+	assert.Nil(err)
+	assert.Equal(3, pool.Status().Busy)
+
+	// an interactive worker being released does not go to the background request
+	releaseInteractive()
+	assert.Equal(1, pool.Status().Queues[upstreamPriorityBackground].Depth)
+	assert.Len(acquired, 0)
+
+	// a background worker being released does
+	releaseFirst()
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("the background request did not get a worker")
+	}
+
+	// the reserved workers leave at least one worker for background requests
+	assert.Equal(0, newUpstreamWorkerPool(1, 1).Status().Reserved)
+}
+
+func TestUpstreamWorkerPoolRaise(t *testing.T) {
+	assert := assert.New(t)
+
+	pool := newUpstreamWorkerPool(1, 0)
+	release, err := pool.Acquire(context.Background())
+	assert.Nil(err)
+
+	order := make(chan string, 2)
+	acquire := func(name string, ctx context.Context) {
+		if release, err := pool.Acquire(ctx); err == nil {
+			order <- name
+			release()
+		}
+	}
+
+	go acquire("background", withUpstreamPriority(context.Background(), upstreamPriorityBackground))
+	assert.Eventually(func() bool { return pool.Status().Queues[upstreamPriorityBackground].Depth == 1 }, time.Second, time.Millisecond)
+
+	shared := newSharedUpstreamPriority(upstreamPriorityBackground)
+	go acquire("shared", withSharedUpstreamPriority(context.Background(), shared))
+	assert.Eventually(func() bool { return pool.Status().Queues[upstreamPriorityBackground].Depth == 2 }, time.Second, time.Millisecond)
+
+	// the raised request moves to the interactive queue and gets the worker first
+	shared.Raise(upstreamPriorityInteractive)
+	assert.Eventually(func() bool { return pool.Status().Queues[upstreamPriorityInteractive].Depth == 1 }, time.Second, time.Millisecond)
+	assert.Equal(1, pool.Status().Queues[upstreamPriorityBackground].Depth)
+
+	release()
+	assert.Equal("shared", <-order)
+	assert.Equal("background", <-order)
+
+	// the priority is never lowered
+	shared.Raise(upstreamPriorityBackground)
+	assert.Equal(upstreamPriorityInteractive, upstreamPriorityFromContext(withSharedUpstreamPriority(context.Background(), shared)))
+}
@@ -0,0 +1,55 @@
+// faketibia serves the static test files like tibia.com and assets.tibiadata.com
+//
+// Run the API against it with:
+//
+//	TIBIADATA_PROXY=localhost:8081 TIBIADATA_PROXY_PROTOCOL=http TIBIADATA_ASSETS_URL=http://localhost:8081/
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/TibiaData/tibiadata-api-go/src/faketibia"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8081", "address to listen on")
+	testdata := flag.String("testdata", "", "directory with pages in the layout of src/static/testdata (default: the embedded test files)")
+	mapping := flag.String("mapping", "", "data.min.json to serve instead of the one built from the pages")
+	fallback := flag.Bool("fallback", false, "serve another page of the same section if a page does not exist (missing pages are answered with 404 otherwise)")
+	maintenance := flag.Bool("maintenance", false, "redirect all pages to maintenance.tibia.com")
+	throttled := flag.Bool("throttled", false, "answer all pages with 403")
+	delay := flag.Duration("delay", 0, "delay of all pages")
+	flag.Parse()
+
+	var (
+		server *faketibia.Server
+		err    error
+	)
+	if *testdata != "" {
+		server, err = faketibia.New(os.DirFS(*testdata))
+	} else {
+		server, err = faketibia.NewFromTestFiles()
+	}
+	if err != nil {
+		log.Fatalf("[error] faketibia: %s", err)
+	}
+
+	if *mapping != "" {
+		data, err := os.ReadFile(*mapping)
+		if err != nil {
+			log.Fatalf("[error] faketibia: %s", err)
+		}
+		server.SetMapping(data)
+	}
+
+	server.Fallback = *fallback
+	server.SetMaintenance(*maintenance)
+	server.SetThrottled(*throttled)
+	server.SetDelay(*delay)
+
+	log.Printf("[info] faketibia listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
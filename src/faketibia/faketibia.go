@@ -0,0 +1,287 @@
+// Package faketibia serves the pages of the static test files like tibia.com
+// and the mapping files like assets.tibiadata.com, so the API can run without them
+package faketibia
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/TibiaData/tibiadata-api-go/src/static"
+)
+
+// Server is a http.Handler pretending to be tibia.com and assets.tibiadata.com
+//
+// The behaviour can be switched at runtime with the methods of Server or with requests to
+// /faketibia/maintenance?enabled=true, /faketibia/throttled?enabled=true and /faketibia/delay?duration=2s
+type Server struct {
+	// Fallback serves another page of the same section if a page does not exist
+	// so requests for any character, guild, world etc. succeed (disabled by default,
+	// a missing page is answered with 404 like tibia.com does, so requests with wrong paths are noticed)
+	Fallback bool
+
+	files fs.FS
+
+	maintenance atomic.Bool
+	throttled   atomic.Bool
+	delay       atomic.Int64
+
+	mapping   []byte
+	sha256Sum string
+	sha512Sum string
+}
+
+// New returns a Server for the files in the layout of static.TestFiles (without the testdata directory)
+// the mapping files are built from the pages in files
+func New(files fs.FS) (*Server, error) {
+	mapping, err := json.Marshal(buildMapping(files))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		files: files,
+	}
+	s.SetMapping(mapping)
+
+	return s, nil
+}
+
+// NewFromTestFiles returns a Server for static.TestFiles
+func NewFromTestFiles() (*Server, error) {
+	files, err := fs.Sub(static.TestFiles, "testdata")
+	if err != nil {
+		return nil, err
+	}
+
+	return New(files)
+}
+
+// SetMapping replaces the content of data.min.json (e.g. with a copy of the real file)
+func (s *Server) SetMapping(mapping []byte) {
+	s.mapping = mapping
+	s.sha256Sum = fmt.Sprintf("%x  data.json\n%x  data.min.json\n", sha256.Sum256(mapping), sha256.Sum256(mapping))
+	s.sha512Sum = fmt.Sprintf("%x  data.json\n%x  data.min.json\n", sha512.Sum512(mapping), sha512.Sum512(mapping))
+}
+
+// SetMaintenance switches redirects of all pages to maintenance.tibia.com on or off
+func (s *Server) SetMaintenance(enabled bool) {
+	s.maintenance.Store(enabled)
+}
+
+// SetThrottled switches 403 responses of all pages on or off
+func (s *Server) SetThrottled(enabled bool) {
+	s.throttled.Store(enabled)
+}
+
+// SetDelay sets how long all pages take to respond
+func (s *Server) SetDelay(delay time.Duration) {
+	s.delay.Store(int64(delay))
+}
+
+// ServeHTTP serves the switches, the mapping files and the pages
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/faketibia/maintenance", "/faketibia/throttled", "/faketibia/delay":
+		s.serveSwitch(w, r)
+		return
+	case "/data.min.json":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(s.mapping)
+		return
+	case "/sha256sum.txt":
+		_, _ = w.Write([]byte(s.sha256Sum))
+		return
+	case "/sha512sum.txt":
+		_, _ = w.Write([]byte(s.sha512Sum))
+		return
+	}
+
+	if delay := time.Duration(s.delay.Load()); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if s.maintenance.Load() {
+		http.Redirect(w, r, "https://maintenance.tibia.com/", http.StatusFound)
+		return
+	}
+
+	if s.throttled.Load() {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	_ = r.ParseForm()
+	data, err := s.page(static.TestFilePath(r.URL.Query(), r.PostForm) + ".html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// tibia.com serves its pages in ISO-8859-1
+	w.Header().Set("Content-Type", "text/html; charset=ISO-8859-1")
+	_, _ = w.Write(static.EncodeLatin1(data))
+}
+
+// serveSwitch changes a switch with a request
+func (s *Server) serveSwitch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	switch path.Base(r.URL.Path) {
+	case "maintenance":
+		enabled, _ := strconv.ParseBool(query.Get("enabled"))
+		s.SetMaintenance(enabled)
+	case "throttled":
+		enabled, _ := strconv.ParseBool(query.Get("enabled"))
+		s.SetThrottled(enabled)
+	case "delay":
+		delay, err := time.ParseDuration(query.Get("duration"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.SetDelay(delay)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// page returns the page at name or, with Fallback, the first page of the closest directory
+func (s *Server) page(name string) ([]byte, error) {
+	data, err := fs.ReadFile(s.files, name)
+	if err == nil || !s.Fallback {
+		return data, err
+	}
+
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		var fallback string
+		_ = fs.WalkDir(s.files, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path.Ext(p) != ".html" {
+				return nil
+			}
+			fallback = p
+			return fs.SkipAll
+		})
+
+		if fallback != "" {
+			return fs.ReadFile(s.files, fallback)
+		}
+	}
+
+	return nil, fs.ErrNotExist
+}
+
+// mapping is the content of data.min.json
+type mapping struct {
+	Worlds    []string          `json:"worlds"`
+	Towns     []string          `json:"towns"`
+	Houses    []mappingHouse    `json:"houses"`
+	Creatures []mappingCreature `json:"creatures"`
+	Spells    []mappingSpell    `json:"spells"`
+}
+
+type mappingHouse struct {
+	ID   int    `json:"house_id"`
+	Town string `json:"town"`
+	Type string `json:"type"`
+}
+
+type mappingCreature struct {
+	Endpoint   string `json:"endpoint"`
+	PluralName string `json:"plural_name"`
+	Name       string `json:"name"`
+}
+
+type mappingSpell struct {
+	Name     string `json:"name"`
+	Formula  string `json:"formula"`
+	Endpoint string `json:"endpoint"`
+}
+
+var (
+	worldRegex    = regexp.MustCompile(`subtopic=worlds&(?:amp;)?world=([^"&]+)`)
+	creatureRegex = regexp.MustCompile(`race=([a-z0-9]+)"><img[^>]*></a> <div>([^<]+)</div>`)
+	spellRegex    = regexp.MustCompile(`(?i)spell=([a-z0-9]+)&[^"]*">([^<]+)</a> \(([^)]+)\)`)
+	houseRegex    = regexp.MustCompile(`name="town" value="([^"]+)" />.*?name="type" value="([^"]+)" />.*?name="houseid" value="(\d+)"`)
+)
+
+// buildMapping returns the worlds, towns, houses, creatures and spells found in the pages of files
+func buildMapping(files fs.FS) mapping {
+	var m mapping
+
+	worlds, _ := fs.ReadFile(files, "worlds/worlds.html")
+	for _, match := range worldRegex.FindAllStringSubmatch(string(worlds), -1) {
+		if world, err := url.QueryUnescape(match[1]); err == nil {
+			m.Worlds = appendUnique(m.Worlds, world)
+		}
+	}
+
+	creatures, _ := fs.ReadFile(files, "creatures/creatures.html")
+	for _, match := range creatureRegex.FindAllStringSubmatch(string(creatures), -1) {
+		m.Creatures = append(m.Creatures, mappingCreature{
+			Endpoint:   match[1],
+			PluralName: match[2],
+			Name:       strings.TrimSuffix(match[2], "s"),
+		})
+	}
+
+	spells, _ := fs.ReadFile(files, "spells/overviewall.html")
+	for _, match := range spellRegex.FindAllStringSubmatch(string(spells), -1) {
+		m.Spells = append(m.Spells, mappingSpell{
+			Endpoint: match[1],
+			Name:     match[2],
+			Formula:  match[3],
+		})
+	}
+
+	houseIDs := map[int]bool{}
+	overviews, _ := fs.Glob(files, "houses/overview/*.html")
+	for _, overview := range overviews {
+		data, _ := fs.ReadFile(files, overview)
+		for _, match := range houseRegex.FindAllStringSubmatch(string(data), -1) {
+			m.Towns = appendUnique(m.Towns, match[1])
+
+			id, _ := strconv.Atoi(match[3])
+			if houseIDs[id] {
+				continue
+			}
+			houseIDs[id] = true
+
+			houseType := "house"
+			if match[2] == "guildhalls" {
+				houseType = "guildhall"
+			}
+			m.Houses = append(m.Houses, mappingHouse{ID: id, Town: match[1], Type: houseType})
+		}
+	}
+
+	sort.Strings(m.Towns)
+
+	return m
+}
+
+// appendUnique appends value to values if it is not in values yet
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
@@ -0,0 +1,115 @@
+package faketibia
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapping(t *testing.T) {
+	assert := assert.New(t)
+
+	server, err := NewFromTestFiles()
+	assert.Nil(err)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/data.min.json", nil))
+	assert.Equal(http.StatusOK, w.Code)
+
+	var m mapping
+	assert.Nil(json.Unmarshal(w.Body.Bytes(), &m))
+	assert.Contains(m.Worlds, "Antica")
+	assert.Contains(m.Towns, "Thais")
+	assert.Contains(m.Houses, mappingHouse{ID: 10301, Town: "Thais", Type: "house"})
+	assert.Contains(m.Creatures, mappingCreature{Endpoint: "demon", PluralName: "Demons", Name: "Demon"})
+	assert.Contains(m.Spells, mappingSpell{Name: "Annihilation", Formula: "exori gran ico", Endpoint: "annihilation"})
+
+	// the sums use the format of assets.tibiadata.com
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sha256sum.txt", nil))
+	fields := strings.Fields(w.Body.String())
+	assert.Len(fields, 4)
+	assert.Equal("data.min.json", fields[3])
+}
+
+func TestPages(t *testing.T) {
+	assert := assert.New(t)
+
+	server, err := NewFromTestFiles()
+	assert.Nil(err)
+
+	get := func(rawURL string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, rawURL, nil))
+		return w
+	}
+
+	// pages are served in ISO-8859-1 like tibia.com does
+	w := get("/community/?subtopic=characters&name=" + url.QueryEscape("Torbjörn"))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("text/html; charset=ISO-8859-1", w.Header().Get("Content-Type"))
+	assert.Contains(w.Body.String(), "Torbj\xf6rn")
+
+	// missing pages are not found
+	w = get("/community/?subtopic=characters&name=Durin")
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	// unless other pages of the section are served for unknown names
+	server.Fallback = true
+	w = get("/community/?subtopic=characters&name=Durin")
+	assert.Equal(http.StatusOK, w.Code)
+	server.Fallback = false
+
+	// POST requests are matched with their form data
+	w = httptest.NewRecorder()
+	form := url.Values{
+		"filter_begin_day": {"14"}, "filter_begin_month": {"12"}, "filter_begin_year": {"2021"},
+		"filter_end_day": {"13"}, "filter_end_month": {"1"}, "filter_end_year": {"2022"},
+	}
+	for _, filter := range []string{"article", "cipsoft", "community", "development", "news", "support", "technical", "ticker"} {
+		form.Set("filter_"+filter, filter)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/news/?subtopic=newsarchive", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	server.ServeHTTP(w, request)
+	assert.Equal(http.StatusOK, w.Code)
+}
+
+func TestSwitches(t *testing.T) {
+	assert := assert.New(t)
+
+	server, err := NewFromTestFiles()
+	assert.Nil(err)
+
+	get := func(rawURL string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, rawURL, nil))
+		return w
+	}
+
+	assert.Equal(http.StatusNoContent, get("/faketibia/maintenance?enabled=true").Code)
+	w := get("/community/?subtopic=worlds")
+	assert.Equal(http.StatusFound, w.Code)
+	assert.Equal("https://maintenance.tibia.com/", w.Header().Get("Location"))
+
+	// the mapping files are not affected by the switches
+	assert.Equal(http.StatusOK, get("/data.min.json").Code)
+
+	get("/faketibia/maintenance?enabled=false")
+	get("/faketibia/throttled?enabled=true")
+	assert.Equal(http.StatusForbidden, get("/community/?subtopic=worlds").Code)
+
+	get("/faketibia/throttled?enabled=false")
+	assert.Equal(http.StatusBadRequest, get("/faketibia/delay?duration=soon").Code)
+	get("/faketibia/delay?duration=50ms")
+
+	start := time.Now()
+	assert.Equal(http.StatusOK, get("/community/?subtopic=worlds").Code)
+	assert.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+}
@@ -0,0 +1,197 @@
+package static
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TestFilePath returns the path of the test file (without extension) of a request to tibia.com
+// query and form are the query string and form data of the request
+//
+// Every parameter of a request is part of its path, so recordings of different requests never overwrite each other:
+//
+//	subtopic=boostablebosses                                                   boostablebosses/boostablebosses
+//	subtopic=characters&name=<name>                                            characters/<name>
+//	subtopic=creatures                                                         creatures/creatures
+//	subtopic=creatures&race=<race>                                             creatures/creature/<race>
+//	subtopic=fansites                                                          fansites/all
+//	subtopic=guilds&world=<world>                                              guilds/<world>
+//	subtopic=guilds&page=view&GuildName=<guild>                                guilds/guild/<guild>
+//	subtopic=highscores&world=<w>&category=<c>&profession=<p>&currentpage=<n>  highscores/<w or all>/<c>-<p>-<n>
+//	subtopic=houses&world=<world>&town=<town>&type=houses                      houses/overview/<world><town>Houses
+//	subtopic=houses&world=<world>&town=<town>&type=guildhalls                  houses/overview/<world><town>Guilds
+//	subtopic=houses&page=view&world=<world>&houseid=<id>                       houses/<world>/<id>
+//	subtopic=killstatistics&world=<world>                                      killstatistics/<world>
+//	subtopic=newsarchive&id=<id>                                               news/archive/<id>
+//	subtopic=newsarchive with a form                                           news/newslist/<begin>_<end>-<filters>
+//	subtopic=spells&vocation=<vocation>                                        spells/overview<vocation in lower case or all>
+//	subtopic=spells&spell=<spell>                                              spells/spell/<spell>
+//	subtopic=worlds                                                            worlds/worlds
+//	subtopic=worlds&world=<world>                                              worlds/world/<world>
+//	any other query                                                            other/<encoded query>
+//
+// Creatures and spells are requested with their endpoint (the name in lower case without spaces), like lavablob.
+// The period of a news list is formatted as 2006-01-02 and its filters are the sorted names of the other
+// form fields without the filter_ prefix, e.g. news/newslist/2021-12-14_2022-01-13-article-news-ticker
+//
+// The test files saved before this layout keep their names, the requests of them are mapped by testFileNames.
+func TestFilePath(query, form url.Values) string {
+	var path string
+
+	switch subtopic := query.Get("subtopic"); subtopic {
+	case "boostablebosses":
+		path = "boostablebosses/boostablebosses"
+	case "characters":
+		path = "characters/" + fileName(query.Get("name"))
+	case "creatures":
+		if race := query.Get("race"); race != "" {
+			path = "creatures/creature/" + fileName(race)
+		} else {
+			path = "creatures/creatures"
+		}
+	case "fansites":
+		path = "fansites/all"
+	case "guilds":
+		if guild := query.Get("GuildName"); guild != "" {
+			path = "guilds/guild/" + fileName(guild)
+		} else {
+			path = "guilds/" + fileName(query.Get("world"))
+		}
+	case "highscores":
+		world := query.Get("world")
+		if world == "" {
+			world = "all"
+		}
+		path = "highscores/" + fileName(world) + "/" + fileName(strings.Join([]string{query.Get("category"), query.Get("profession"), query.Get("currentpage")}, "-"))
+	case "houses":
+		if houseID := query.Get("houseid"); houseID != "" {
+			path = "houses/" + fileName(query.Get("world")) + "/" + fileName(houseID)
+		} else {
+			houseType := "Houses"
+			if query.Get("type") == "guildhalls" {
+				houseType = "Guilds"
+			}
+			path = "houses/overview/" + fileName(query.Get("world")+query.Get("town")+houseType)
+		}
+	case "killstatistics":
+		path = "killstatistics/" + fileName(query.Get("world"))
+	case "newsarchive":
+		if id := query.Get("id"); id != "" {
+			path = "news/archive/" + fileName(id)
+		} else {
+			path = "news/newslist/" + fileName(newslistName(form))
+		}
+	case "spells":
+		if spell := query.Get("spell"); spell != "" {
+			path = "spells/spell/" + fileName(spell)
+		} else {
+			vocation := strings.ToLower(query.Get("vocation"))
+			if vocation == "" {
+				vocation = "all"
+			}
+			path = "spells/overview" + fileName(vocation)
+		}
+	case "worlds":
+		if world := query.Get("world"); world != "" {
+			path = "worlds/world/" + fileName(world)
+		} else {
+			path = "worlds/worlds"
+		}
+	default:
+		path = "other/" + fileName(query.Encode())
+	}
+
+	if name, ok := testFileNames[path]; ok {
+		return name
+	}
+
+	return path
+}
+
+// testFileNames maps the paths of requests to the names of the test files saved by hand
+var testFileNames = map[string]string{
+	"creatures/creature/lavablob":        "creatures/creature/lava lurkers",
+	"creatures/creature/quarapredator":   "creatures/creature/quara predator",
+	"highscores/all/6-0-1":               "highscores/all",
+	"highscores/Vunira/10-5-4":           "highscores/loyalty",
+	"houses/Premia/10214":                "houses/Premia/Thais/BeachHomeApartmentsFlat14",
+	"houses/Premia/10215":                "houses/Premia/Thais/BeachHomeApartmentsFlat15",
+	"houses/Premia/54023":                "houses/Premia/Edron/Cormaya9c",
+	"houses/Premia/54025":                "houses/Premia/Edron/Cormaya10",
+	"houses/Premia/54026":                "houses/Premia/Edron/Cormaya11",
+	"spells/spell/annihilation":          "spells/spell/Annihilation",
+	"spells/spell/bruisebane":            "spells/spell/Bruise Bane",
+	"spells/spell/convincecreaturerune":  "spells/spell/Convince Creature Rune",
+	"spells/spell/curepoisonrune":        "spells/spell/Cure Poison Rune",
+	"spells/spell/findperson":            "spells/spell/Find Person",
+	"spells/spell/heavymagicmissilerune": "spells/spell/Heavy Magic Missile Rune",
+	"news/newslist/2021-12-14_2022-01-13-article-cipsoft-community-development-news-support-technical-ticker": "news/newslist",
+}
+
+// newslistName returns the name of a news list with its period and filters
+// like 2021-12-14_2022-01-13-article-cipsoft-community-development-news-support-technical-ticker
+func newslistName(form url.Values) string {
+	date := func(prefix string) string {
+		year, _ := strconv.Atoi(form.Get(prefix + "_year"))
+		month, _ := strconv.Atoi(form.Get(prefix + "_month"))
+		day, _ := strconv.Atoi(form.Get(prefix + "_day"))
+		return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+	}
+
+	var filters []string
+	for field, values := range form {
+		if strings.HasPrefix(field, "filter_begin_") || strings.HasPrefix(field, "filter_end_") {
+			continue
+		}
+		if len(values) > 0 && values[0] != "" {
+			filters = append(filters, strings.TrimPrefix(field, "filter_"))
+		}
+	}
+	sort.Strings(filters)
+
+	return strings.Join(append([]string{date("filter_begin") + "_" + date("filter_end")}, filters...), "-")
+}
+
+// fileName makes a parameter safe to use as a file name
+// parameters sent in ISO-8859-1 (like names with umlauts) are converted to UTF-8
+func fileName(name string) string {
+	if !utf8.ValidString(name) {
+		name = string(DecodeLatin1([]byte(name)))
+	}
+
+	name = strings.NewReplacer("/", "_", "\\", "_", "\x00", "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// EncodeLatin1 converts a test file (UTF-8) to ISO-8859-1 like the pages served by tibia.com
+// characters not available in ISO-8859-1 are replaced with a question mark
+func EncodeLatin1(data []byte) []byte {
+	encoded := make([]byte, 0, len(data))
+	for _, r := range string(data) {
+		if r > 0xff {
+			r = '?'
+		}
+		encoded = append(encoded, byte(r))
+	}
+
+	return encoded
+}
+
+// DecodeLatin1 converts a page served by tibia.com (ISO-8859-1) to UTF-8 like the test files
+func DecodeLatin1(data []byte) []byte {
+	var decoded strings.Builder
+	decoded.Grow(len(data))
+	for _, c := range data {
+		decoded.WriteRune(rune(c))
+	}
+
+	return []byte(decoded.String())
+}
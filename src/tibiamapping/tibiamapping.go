@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -25,8 +27,20 @@ const (
 
 	// tibiaAssetsSha512SumURL is the endpoint to get the sha512sum.txt file
 	tibiaAssetsSha512SumURL = "https://assets.tibiadata.com/sha512sum.txt"
+
+	// tibiaAssetsURLEnv is the environment variable to load the files from another host (e.g. faketibia)
+	tibiaAssetsURLEnv = "TIBIADATA_ASSETS_URL"
 )
 
+// assetsURL returns url with the host replaced if TIBIADATA_ASSETS_URL is set
+func assetsURL(url string) string {
+	if baseURL := os.Getenv(tibiaAssetsURLEnv); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(url, "https://assets.tibiadata.com/")
+	}
+
+	return url
+}
+
 // Run is used to load data from the assets JSON file
 func Run(userAgent string) (*TibiaMapping, error) {
 	// Logging the start of tibiamapping
@@ -52,7 +66,7 @@ func Run(userAgent string) (*TibiaMapping, error) {
 	client.SetRedirectPolicy(resty.NoRedirectPolicy())
 
 	// Making the GET request to the data file
-	res, err := client.R().Get(tibiaAssetsDataMinJsonURL)
+	res, err := client.R().Get(assetsURL(tibiaAssetsDataMinJsonURL))
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +77,7 @@ func Run(userAgent string) (*TibiaMapping, error) {
 	}
 
 	// Making the GET request to the sha256 file
-	sha256, err := client.R().Get(tibiaAssetsSha256SumURL)
+	sha256, err := client.R().Get(assetsURL(tibiaAssetsSha256SumURL))
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +88,7 @@ func Run(userAgent string) (*TibiaMapping, error) {
 	}
 
 	// Making the GET request to the sha512 file
-	sha512, err := client.R().Get(tibiaAssetsSha512SumURL)
+	sha512, err := client.R().Get(assetsURL(tibiaAssetsSha512SumURL))
 	if err != nil {
 		return nil, err
 	}
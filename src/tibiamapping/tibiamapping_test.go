@@ -19,6 +19,14 @@ func TestFakeTest(t *testing.T) {
 	}
 }
 
+func TestAssetsURL(t *testing.T) {
+	t.Setenv("TIBIADATA_ASSETS_URL", "http://localhost:8081/")
+
+	if url := assetsURL(tibiaAssetsDataMinJsonURL); url != "http://localhost:8081/data.min.json" {
+		t.Errorf("assetsURL returned %s", url)
+	}
+}
+
 func TestInitiator(t *testing.T) {
 	mapping, err := Run("TibiaData-API/v4/testing")
 	if err != nil {
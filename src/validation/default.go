@@ -0,0 +1,161 @@
+package validation
+
+// Initiate calls Initiate of the default Validator
+func Initiate(TibiaDataUserAgent string) error {
+	return defaultValidator.Initiate(TibiaDataUserAgent)
+}
+
+// Reload calls Reload of the default Validator
+func Reload(TibiaDataUserAgent string) error {
+	return defaultValidator.Reload(TibiaDataUserAgent)
+}
+
+// IsCreatureNameValid calls IsCreatureNameValid of the default Validator
+func IsCreatureNameValid(name string) (string, error) {
+	return defaultValidator.IsCreatureNameValid(name)
+}
+
+// IsSpellNameOrFormulaValid calls IsSpellNameOrFormulaValid of the default Validator
+func IsSpellNameOrFormulaValid(name string) (string, error) {
+	return defaultValidator.IsSpellNameOrFormulaValid(name)
+}
+
+// GetWorlds calls GetWorlds of the default Validator
+func GetWorlds() ([]string, error) {
+	return defaultValidator.GetWorlds()
+}
+
+// WorldExists calls WorldExists of the default Validator
+func WorldExists(world string) (bool, error) {
+	return defaultValidator.WorldExists(world)
+}
+
+// GetTowns calls GetTowns of the default Validator
+func GetTowns() ([]string, error) {
+	return defaultValidator.GetTowns()
+}
+
+// TownExists calls TownExists of the default Validator
+func TownExists(town string) (bool, error) {
+	return defaultValidator.TownExists(town)
+}
+
+// GetHouses calls GetHouses of the default Validator
+func GetHouses() ([]House, error) {
+	return defaultValidator.GetHouses()
+}
+
+// GetHouseRaw calls GetHouseRaw of the default Validator
+func GetHouseRaw(houseID int) (*House, error) {
+	return defaultValidator.GetHouseRaw(houseID)
+}
+
+// HouseExistsRaw calls HouseExistsRaw of the default Validator
+func HouseExistsRaw(houseID int) (bool, error) {
+	return defaultValidator.HouseExistsRaw(houseID)
+}
+
+// GetHouseInTown calls GetHouseInTown of the default Validator
+func GetHouseInTown(houseID int, town string) (*House, error) {
+	return defaultValidator.GetHouseInTown(houseID, town)
+}
+
+// HouseExistsInTown calls HouseExistsInTown of the default Validator
+func HouseExistsInTown(houseID int, town string) (bool, error) {
+	return defaultValidator.HouseExistsInTown(houseID, town)
+}
+
+// GetCreatures calls GetCreatures of the default Validator
+func GetCreatures() ([]Creature, error) {
+	return defaultValidator.GetCreatures()
+}
+
+// GetSha256Sum calls GetSha256Sum of the default Validator
+func GetSha256Sum() (string, error) {
+	return defaultValidator.GetSha256Sum()
+}
+
+// GetSha512Sum calls GetSha512Sum of the default Validator
+func GetSha512Sum() (string, error) {
+	return defaultValidator.GetSha512Sum()
+}
+
+// GetSmallestCreatureName calls GetSmallestCreatureName of the default Validator
+func GetSmallestCreatureName() (string, error) {
+	return defaultValidator.GetSmallestCreatureName()
+}
+
+// GetBiggestCreatureName calls GetBiggestCreatureName of the default Validator
+func GetBiggestCreatureName() (string, error) {
+	return defaultValidator.GetBiggestCreatureName()
+}
+
+// GetBiggestCreatureWord calls GetBiggestCreatureWord of the default Validator
+func GetBiggestCreatureWord() (string, error) {
+	return defaultValidator.GetBiggestCreatureWord()
+}
+
+// GetSmallestCreatureWord calls GetSmallestCreatureWord of the default Validator
+func GetSmallestCreatureWord() (string, error) {
+	return defaultValidator.GetSmallestCreatureWord()
+}
+
+// GetSmallestCreatureNameRuneCount calls GetSmallestCreatureNameRuneCount of the default Validator
+func GetSmallestCreatureNameRuneCount() (int, error) {
+	return defaultValidator.GetSmallestCreatureNameRuneCount()
+}
+
+// GetBiggestCreatureNameRuneCount calls GetBiggestCreatureNameRuneCount of the default Validator
+func GetBiggestCreatureNameRuneCount() (int, error) {
+	return defaultValidator.GetBiggestCreatureNameRuneCount()
+}
+
+// GetSmallestCreatureWordRuneCount calls GetSmallestCreatureWordRuneCount of the default Validator
+func GetSmallestCreatureWordRuneCount() (int, error) {
+	return defaultValidator.GetSmallestCreatureWordRuneCount()
+}
+
+// GetBiggestCreatureWordRuneCount calls GetBiggestCreatureWordRuneCount of the default Validator
+func GetBiggestCreatureWordRuneCount() (int, error) {
+	return defaultValidator.GetBiggestCreatureWordRuneCount()
+}
+
+// GetSmallestSpellNameOrFormula calls GetSmallestSpellNameOrFormula of the default Validator
+func GetSmallestSpellNameOrFormula() (string, error) {
+	return defaultValidator.GetSmallestSpellNameOrFormula()
+}
+
+// GetBiggestSpellNameOrFormula calls GetBiggestSpellNameOrFormula of the default Validator
+func GetBiggestSpellNameOrFormula() (string, error) {
+	return defaultValidator.GetBiggestSpellNameOrFormula()
+}
+
+// GetBiggestSpellWord calls GetBiggestSpellWord of the default Validator
+func GetBiggestSpellWord() (string, error) {
+	return defaultValidator.GetBiggestSpellWord()
+}
+
+// GetSmallestSpellWord calls GetSmallestSpellWord of the default Validator
+func GetSmallestSpellWord() (string, error) {
+	return defaultValidator.GetSmallestSpellWord()
+}
+
+// GetSmallestSpellNameOrFormulaRuneCount calls GetSmallestSpellNameOrFormulaRuneCount of the default Validator
+func GetSmallestSpellNameOrFormulaRuneCount() (int, error) {
+	return defaultValidator.GetSmallestSpellNameOrFormulaRuneCount()
+}
+
+// GetBiggestSpellNameOrFormulaRuneCount calls GetBiggestSpellNameOrFormulaRuneCount of the default Validator
+func GetBiggestSpellNameOrFormulaRuneCount() (int, error) {
+	return defaultValidator.GetBiggestSpellNameOrFormulaRuneCount()
+}
+
+// GetSmallestSpellWordRuneCount calls GetSmallestSpellWordRuneCount of the default Validator
+func GetSmallestSpellWordRuneCount() (int, error) {
+	return defaultValidator.GetSmallestSpellWordRuneCount()
+}
+
+// GetBiggestSpellWordRuneCount calls GetBiggestSpellWordRuneCount of the default Validator
+func GetBiggestSpellWordRuneCount() (int, error) {
+	return defaultValidator.GetBiggestSpellWordRuneCount()
+}
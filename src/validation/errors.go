@@ -30,6 +30,10 @@ var (
 	// Code: 9001
 	ErrorStringCanNotBeConvertedToInt = Error{errors.New("the provided string can not be converted to an integer")}
 
+	// ErrorStringCanNotBeEncoded will be sent if the request contains characters tibia.com can not represent
+	// Code: 9002
+	ErrorStringCanNotBeEncoded = Error{errors.New("the provided string contains characters that can not be represented on tibia.com")}
+
 	// ErrorCharacterNameEmpty will be sent if the request contains an empty character name
 	// Code: 10001
 	ErrorCharacterNameEmpty = Error{errors.New("the provided character name is an empty string")}
@@ -174,6 +178,38 @@ var (
 	// Code: 14007
 	ErrorGuildWordTooSmall = Error{errors.New("the provided guild name has a word too smal")}
 
+	// ErrorAPIKeyRequired will be sent if API keys are enabled and the request does not contain one
+	// Code: 15001
+	ErrorAPIKeyRequired = Error{errors.New("an API key is required")}
+
+	// ErrorAPIKeyInvalid will be sent if the provided API key does not exist
+	// Code: 15002
+	ErrorAPIKeyInvalid = Error{errors.New("the provided API key is invalid")}
+
+	// ErrorAPIKeyRouteNotAllowed will be sent if the provided API key is not allowed to access the route
+	// Code: 15003
+	ErrorAPIKeyRouteNotAllowed = Error{errors.New("the provided API key is not allowed to access this route")}
+
+	// ErrorAPIKeyMinuteQuotaExceeded will be sent if the provided API key has used up its requests of the minute
+	// Code: 15004
+	ErrorAPIKeyMinuteQuotaExceeded = Error{errors.New("the provided API key has exceeded its requests per minute")}
+
+	// ErrorAPIKeyDailyQuotaExceeded will be sent if the provided API key has used up its requests of the day
+	// Code: 15005
+	ErrorAPIKeyDailyQuotaExceeded = Error{errors.New("the provided API key has exceeded its requests per day")}
+
+	// ErrorRateLimitExceeded will be sent if the client sent more requests than the rate limit allows
+	// Code: 15006
+	ErrorRateLimitExceeded = Error{errors.New("too many requests, try again later")}
+
+	// ErrorAdminCachePurgeTargetMissing will be sent if a cache purge contains neither a key nor a prefix
+	// Code: 15007
+	ErrorAdminCachePurgeTargetMissing = Error{errors.New("a key or prefix is required to purge the cache")}
+
+	// ErrorAdminDebugValueInvalid will be sent if the debug mode is set to a value which is not a boolean
+	// Code: 15008
+	ErrorAdminDebugValueInvalid = Error{errors.New("the provided debug mode is not true or false")}
+
 	///////////////////
 	// Tibia Errors //
 	/////////////////
@@ -197,6 +233,26 @@ var (
 	// ErrorMaintenanceMode will be sent if there is ongoing maintenance
 	// Code: 20005
 	ErrorMaintenanceMode = Error{errors.New("maintenance mode active")}
+
+	// ErrorUpstreamThrottled will be sent if requests to tibia.com are throttled
+	// Code: 20006
+	ErrorUpstreamThrottled = Error{errors.New("requests to tibia.com are throttled, try again later")}
+
+	// ErrorUpstreamUnavailable will be sent if requests to tibia.com keep failing
+	// Code: 20007
+	ErrorUpstreamUnavailable = Error{errors.New("tibia.com is unavailable, try again later")}
+
+	// ErrorUpstreamErrorPage will be sent if tibia.com answers with an error page
+	// Code: 20008
+	ErrorUpstreamErrorPage = Error{errors.New("tibia.com returned an error page")}
+
+	// ErrorUpstreamPageNotFound will be sent if tibia.com answers with a not found page
+	// Code: 20009
+	ErrorUpstreamPageNotFound = Error{errors.New("tibia.com could not find the page")}
+
+	// ErrorUpstreamUnknownLayout will be sent if the page of tibia.com has an unknown layout or no data could be parsed from it
+	// Code: 20010
+	ErrorUpstreamUnknownLayout = Error{errors.New("tibia.com returned a page with an unknown layout")}
 )
 
 // Code will return the code of the error
@@ -208,6 +264,8 @@ func (e Error) Code() int {
 		return 11
 	case ErrorStringCanNotBeConvertedToInt:
 		return 9001
+	case ErrorStringCanNotBeEncoded:
+		return 9002
 	case ErrorCharacterNameEmpty:
 		return 10001
 	case ErrorCharacterNameTooSmall:
@@ -280,6 +338,22 @@ func (e Error) Code() int {
 		return 14006
 	case ErrorGuildWordTooSmall:
 		return 14007
+	case ErrorAPIKeyRequired:
+		return 15001
+	case ErrorAPIKeyInvalid:
+		return 15002
+	case ErrorAPIKeyRouteNotAllowed:
+		return 15003
+	case ErrorAPIKeyMinuteQuotaExceeded:
+		return 15004
+	case ErrorAPIKeyDailyQuotaExceeded:
+		return 15005
+	case ErrorRateLimitExceeded:
+		return 15006
+	case ErrorAdminCachePurgeTargetMissing:
+		return 15007
+	case ErrorAdminDebugValueInvalid:
+		return 15008
 	case ErrorCharacterNotFound:
 		return 20001
 	case ErrorCreatureNotFound:
@@ -290,6 +364,16 @@ func (e Error) Code() int {
 		return 20004
 	case ErrorMaintenanceMode:
 		return 20005
+	case ErrorUpstreamThrottled:
+		return 20006
+	case ErrorUpstreamUnavailable:
+		return 20007
+	case ErrorUpstreamErrorPage:
+		return 20008
+	case ErrorUpstreamPageNotFound:
+		return 20009
+	case ErrorUpstreamUnknownLayout:
+		return 20010
 	default:
 		return 0
 	}
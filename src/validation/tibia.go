@@ -129,9 +129,12 @@ func IsGuildNameValid(name string) error {
 // IsCreatureNameValid reports wheter the provided string represents a valid creature name
 // Check if error == nil to see whether the creature is valid or not
 // It will also return the creature endpoint
-func IsCreatureNameValid(name string) (string, error) {
+func (v *Validator) IsCreatureNameValid(name string) (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
@@ -141,9 +144,9 @@ func IsCreatureNameValid(name string) (string, error) {
 	switch {
 	case lenName == 0: // Name is an empty string
 		return "", ErrorCreatureNameEmpty
-	case lenName < smallestCreatureNameRuneCount: // Name is too small
+	case lenName < v.smallestCreatureNameRuneCount: // Name is too small
 		return "", ErrorCreatureNameTooSmall
-	case lenName > biggestCreatureNameRuneCount: // Name is too big
+	case lenName > v.biggestCreatureNameRuneCount: // Name is too big
 		return "", ErrorCreatureNameTooBig
 	}
 
@@ -157,11 +160,11 @@ func IsCreatureNameValid(name string) (string, error) {
 	for _, str := range strs {
 		utfCount := utf8.RuneCountInString(str)
 
-		if utfCount > biggestCreatureWordRuneCount {
+		if utfCount > v.biggestCreatureWordRuneCount {
 			return "", ErrorCreatureWordTooBig
 		}
 
-		if utfCount < smallestCreatureWordRuneCount {
+		if utfCount < v.smallestCreatureWordRuneCount {
 			return "", ErrorCreatureWordTooSmall
 		}
 	}
@@ -178,7 +181,7 @@ func IsCreatureNameValid(name string) (string, error) {
 	)
 
 	// Check if creature exists
-	for _, creature := range val.Creatures {
+	for _, creature := range v.val.Creatures {
 		if strings.EqualFold(name, creature.Endpoint) || strings.EqualFold(name, creature.Name) || strings.EqualFold(name, creature.PluralName) {
 			found = true
 			endpoint = creature.Endpoint
@@ -196,9 +199,12 @@ func IsCreatureNameValid(name string) (string, error) {
 // IsSpellNameOrFormulaValid reports wheter the provided string represents a valid spell name or formula
 // Check if error == nil to see whether the creature is valid or not
 // It will also return the spell endpoint
-func IsSpellNameOrFormulaValid(name string) (string, error) {
+func (v *Validator) IsSpellNameOrFormulaValid(name string) (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
@@ -208,9 +214,9 @@ func IsSpellNameOrFormulaValid(name string) (string, error) {
 	switch {
 	case lenName == 0: // Name is an empty string
 		return "", ErrorSpellNameEmpty
-	case lenName < smallestSpellNameOrFormulaRuneCount: // Name is too small
+	case lenName < v.smallestSpellNameOrFormulaRuneCount: // Name is too small
 		return "", ErrorSpellNameTooSmall
-	case lenName > biggestSpellNameOrFormulaRuneCount: // Name is too big
+	case lenName > v.biggestSpellNameOrFormulaRuneCount: // Name is too big
 		return "", ErrorSpellNameTooBig
 	}
 
@@ -224,11 +230,11 @@ func IsSpellNameOrFormulaValid(name string) (string, error) {
 	for _, str := range strs {
 		utfCount := utf8.RuneCountInString(str)
 
-		if utfCount > biggestSpellWordRuneCount {
+		if utfCount > v.biggestSpellWordRuneCount {
 			return "", ErrorSpellWordTooBig
 		}
 
-		if utfCount < smallestSpellWordRuneCount {
+		if utfCount < v.smallestSpellWordRuneCount {
 			return "", ErrorSpellWordTooSmall
 		}
 	}
@@ -245,7 +251,7 @@ func IsSpellNameOrFormulaValid(name string) (string, error) {
 	)
 
 	// Check if spell exists
-	for _, spell := range val.Spells {
+	for _, spell := range v.val.Spells {
 		if strings.EqualFold(name, spell.Endpoint) || strings.EqualFold(name, spell.Name) || strings.EqualFold(name, spell.Formula) {
 			found = true
 			endpoint = spell.Endpoint
@@ -261,25 +267,31 @@ func IsSpellNameOrFormulaValid(name string) (string, error) {
 }
 
 // GetWorlds returns a list of all existing worlds
-func GetWorlds() ([]string, error) {
+func (v *Validator) GetWorlds() ([]string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return nil, ErrorValidatorNotInitiated
 	}
 
-	return val.Worlds, nil
+	return v.val.Worlds, nil
 }
 
 // WorldExists reports whether the specified world exists
 // This function is case insensitive
-func WorldExists(world string) (bool, error) {
+func (v *Validator) WorldExists(world string) (bool, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return false, ErrorValidatorNotInitiated
 	}
 
 	// Try to find the world
-	for _, w := range val.Worlds {
+	for _, w := range v.val.Worlds {
 		if strings.EqualFold(w, world) {
 			return true, nil
 		}
@@ -289,55 +301,72 @@ func WorldExists(world string) (bool, error) {
 }
 
 // GetTowns returns a list of all existing towns
-func GetTowns() ([]string, error) {
+func (v *Validator) GetTowns() ([]string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return nil, ErrorValidatorNotInitiated
 	}
 
-	return val.Towns, nil
+	return v.val.Towns, nil
 }
 
 // TowndExists reports whether the specified town exists
 // This function is case insensitive
-func TownExists(town string) (bool, error) {
+func (v *Validator) TownExists(town string) (bool, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return false, ErrorValidatorNotInitiated
 	}
 
+	return v.townExists(town), nil
+}
+
+// townExists reports whether the specified town exists, the lock must be held
+func (v *Validator) townExists(town string) bool {
 	// Try to find the town
-	for _, t := range val.Towns {
+	for _, t := range v.val.Towns {
 		if strings.EqualFold(t, town) {
-			return true, nil
+			return true
 		}
 	}
 
-	return false, nil
+	return false
 }
 
 // GetHouses returns a slice of all houses
-func GetHouses() ([]House, error) {
+func (v *Validator) GetHouses() ([]House, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return nil, ErrorValidatorNotInitiated
 	}
 
-	return val.Houses, nil
+	return v.val.Houses, nil
 }
 
 // GetHouseRaw returns a house by it's ID, independently
 // of what town the house is from
 // This function will return a nil house AND a nil error
 // if the specified ID doesn't exist
-func GetHouseRaw(houseID int) (*House, error) {
+func (v *Validator) GetHouseRaw(houseID int) (*House, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return nil, ErrorValidatorNotInitiated
 	}
 
 	// Try to find the house
-	for _, h := range val.Houses {
+	for _, h := range v.val.Houses {
 		if h.ID == houseID {
 			return &h, nil
 		}
@@ -348,8 +377,8 @@ func GetHouseRaw(houseID int) (*House, error) {
 
 // HouseExistsRaw reports whether a house exits, independently
 // of what town the house is from
-func HouseExistsRaw(houseID int) (bool, error) {
-	house, err := GetHouseRaw(houseID)
+func (v *Validator) HouseExistsRaw(houseID int) (bool, error) {
+	house, err := v.GetHouseRaw(houseID)
 	return house != nil, err
 }
 
@@ -357,21 +386,22 @@ func HouseExistsRaw(houseID int) (bool, error) {
 // This function will return a nil house AND a nil error
 // if the specified ID doesn't exist in the specified town
 // or if the specified town doesn't exist
-func GetHouseInTown(houseID int, town string) (*House, error) {
-	// We don't need to check if the validator has been initiated
-	// because TownExists will already check that for us
-	townExists, err := TownExists(town)
-	if err != nil {
-		return nil, err
+func (v *Validator) GetHouseInTown(houseID int, town string) (*House, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
+	// Check if the validator has been initiated
+	if !v.initiated {
+		return nil, ErrorValidatorNotInitiated
 	}
 
 	// Town doesn't exist
-	if !townExists {
+	if !v.townExists(town) {
 		return nil, nil
 	}
 
 	// Try to find the house
-	for _, h := range val.Houses {
+	for _, h := range v.val.Houses {
 		if h.ID == houseID && strings.EqualFold(h.Town, town) {
 			return &h, nil
 		}
@@ -383,17 +413,20 @@ func GetHouseInTown(houseID int, town string) (*House, error) {
 // HouseExistsInTown reports whether a house exits in the specified town
 // This function will return false AND a nil error if the specified ID
 // doesn't exist in the specified town or if the specified town doesn't exist
-func HouseExistsInTown(houseID int, town string) (bool, error) {
-	house, err := GetHouseInTown(houseID, town)
+func (v *Validator) HouseExistsInTown(houseID int, town string) (bool, error) {
+	house, err := v.GetHouseInTown(houseID, town)
 	return house != nil, err
 }
 
 // GetCreatures returns a list of all existing creatures
-func GetCreatures() ([]Creature, error) {
+func (v *Validator) GetCreatures() ([]Creature, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return nil, ErrorValidatorNotInitiated
 	}
 
-	return val.Creatures, nil
+	return v.val.Creatures, nil
 }
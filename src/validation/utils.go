@@ -3,23 +3,29 @@ package validation
 import "unicode"
 
 // GetSha256Sum returns the sha256sum of the data.min.json file being used
-func GetSha256Sum() (string, error) {
+func (v *Validator) GetSha256Sum() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return sha256sum, nil
+	return v.sha256sum, nil
 }
 
 // GetSha512Sum returns the sha512sum of the data.min.json file being used
-func GetSha512Sum() (string, error) {
+func (v *Validator) GetSha512Sum() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return sha512sum, nil
+	return v.sha512sum, nil
 }
 
 // DoesStringContainDigits returns whether there is a digit rune in the string
@@ -45,161 +51,209 @@ func DoesStringContainsNumbers(str string) bool {
 }
 
 // GetSmallestCreatureName returns the name of the creature with the smallest name
-func GetSmallestCreatureName() (string, error) {
+func (v *Validator) GetSmallestCreatureName() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return smallestCreatureName, nil
+	return v.smallestCreatureName, nil
 }
 
 // GetBiggestCreatureName returns the name of the creature with the biggest name
-func GetBiggestCreatureName() (string, error) {
+func (v *Validator) GetBiggestCreatureName() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return biggestCreatureName, nil
+	return v.biggestCreatureName, nil
 }
 
 // GetBiggestCreatureWord returns the biggest word in a creature name
-func GetBiggestCreatureWord() (string, error) {
+func (v *Validator) GetBiggestCreatureWord() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return biggestCreatureWord, nil
+	return v.biggestCreatureWord, nil
 }
 
 // GetSmallestCreatureWord returns the smallest word in a creature name
-func GetSmallestCreatureWord() (string, error) {
+func (v *Validator) GetSmallestCreatureWord() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return smallestCreatureWord, nil
+	return v.smallestCreatureWord, nil
 }
 
 // GetSmallestCreatureNameRuneCount returns the length of the smallest creature name
-func GetSmallestCreatureNameRuneCount() (int, error) {
+func (v *Validator) GetSmallestCreatureNameRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return smallestCreatureNameRuneCount, nil
+	return v.smallestCreatureNameRuneCount, nil
 }
 
 // GetBiggestCreatureNameRuneCount returns the length of the biggest creature name
-func GetBiggestCreatureNameRuneCount() (int, error) {
+func (v *Validator) GetBiggestCreatureNameRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return biggestCreatureNameRuneCount, nil
+	return v.biggestCreatureNameRuneCount, nil
 }
 
 // GetSmallestCreatureWordRuneCount returns the length of the smallest creature word
-func GetSmallestCreatureWordRuneCount() (int, error) {
+func (v *Validator) GetSmallestCreatureWordRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return smallestCreatureWordRuneCount, nil
+	return v.smallestCreatureWordRuneCount, nil
 }
 
 // GetBiggestCreatureWordRuneCount returns the length of the biggest creature word
-func GetBiggestCreatureWordRuneCount() (int, error) {
+func (v *Validator) GetBiggestCreatureWordRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return biggestCreatureWordRuneCount, nil
+	return v.biggestCreatureWordRuneCount, nil
 }
 
 // GetSmallestSpellNameOrFormula returns the name of the spell with the smallest name or formula
-func GetSmallestSpellNameOrFormula() (string, error) {
+func (v *Validator) GetSmallestSpellNameOrFormula() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return smallestSpellNameOrFormula, nil
+	return v.smallestSpellNameOrFormula, nil
 }
 
 // GetBiggestSpellNameOrFormula returns the name of the spell with the biggest name or formula
-func GetBiggestSpellNameOrFormula() (string, error) {
+func (v *Validator) GetBiggestSpellNameOrFormula() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return biggestSpellNameOrFormula, nil
+	return v.biggestSpellNameOrFormula, nil
 }
 
 // GetBiggestSpellWord returns the biggest word in a spell name or formula
-func GetBiggestSpellWord() (string, error) {
+func (v *Validator) GetBiggestSpellWord() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return biggestSpellWord, nil
+	return v.biggestSpellWord, nil
 }
 
 // GetSmallestSpellWord returns the smallest word in a spell name or formula
-func GetSmallestSpellWord() (string, error) {
+func (v *Validator) GetSmallestSpellWord() (string, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return "", ErrorValidatorNotInitiated
 	}
 
-	return smallestSpellWord, nil
+	return v.smallestSpellWord, nil
 }
 
 // GetSmallestSpellNameOrFormulaRuneCount returns the length of the smallest spell name
-func GetSmallestSpellNameOrFormulaRuneCount() (int, error) {
+func (v *Validator) GetSmallestSpellNameOrFormulaRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return smallestSpellNameOrFormulaRuneCount, nil
+	return v.smallestSpellNameOrFormulaRuneCount, nil
 }
 
 // GetBiggestSpellNameOrFormulaRuneCount returns the length of the biggest spell name
-func GetBiggestSpellNameOrFormulaRuneCount() (int, error) {
+func (v *Validator) GetBiggestSpellNameOrFormulaRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return biggestSpellNameOrFormulaRuneCount, nil
+	return v.biggestSpellNameOrFormulaRuneCount, nil
 }
 
 // GetSmallestSpellWordRuneCount returns the length of the smallest spell word
-func GetSmallestSpellWordRuneCount() (int, error) {
+func (v *Validator) GetSmallestSpellWordRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return smallestSpellWordRuneCount, nil
+	return v.smallestSpellWordRuneCount, nil
 }
 
 // GetBiggestSpellWordRuneCount returns the length of the biggest spell word
-func GetBiggestSpellWordRuneCount() (int, error) {
+func (v *Validator) GetBiggestSpellWordRuneCount() (int, error) {
+	v.locker.RLock()
+	defer v.locker.RUnlock()
+
 	// Check if the validator has been initiated
-	if !initiated {
+	if !v.initiated {
 		return -1, ErrorValidatorNotInitiated
 	}
 
-	return biggestSpellWordRuneCount, nil
+	return v.biggestSpellWordRuneCount, nil
 }
@@ -38,37 +38,86 @@ type House struct {
 	Type string `json:"type"`
 }
 
-var (
-	initiated bool             // initiated reports whether the validator has already been initiated
-	val       = validator{}    // val is the local validator that will be read from to get the necessary data
-	locker    = sync.RWMutex{} // locker is a locker to prevent InitiateValidator to be accessed concurrently
-	sha256sum string           // sha256sum stores the sha256sum of the data.min.json file
-	sha512sum string           // sha512sum stores the sha512sum of the data.min.json file
+// Validator validates the requests with the tibiamapping data
+// the package functions use the default Validator, New creates another one (e.g. for each router)
+type Validator struct {
+	initiated bool         // initiated reports whether the validator has already been initiated
+	val       validator    // val is the local validator that will be read from to get the necessary data
+	locker    sync.RWMutex // locker is a locker to prevent the data to be read while it is loaded
+	sha256sum string       // sha256sum stores the sha256sum of the data.min.json file
+	sha512sum string       // sha512sum stores the sha512sum of the data.min.json file
 
 	smallestCreatureName, biggestCreatureName, smallestCreatureWord, biggestCreatureWord                                           string // smallest and biggest creature names and words
 	smallestCreatureNameRuneCount, biggestCreatureNameRuneCount, smallestCreatureWordRuneCount, biggestCreatureWordRuneCount       int    // smallest and biggest creature names and words rune count
 	smallestSpellNameOrFormula, biggestSpellNameOrFormula, smallestSpellWord, biggestSpellWord                                     string // smalles and biggest spell names or formulas and words
 	smallestSpellNameOrFormulaRuneCount, biggestSpellNameOrFormulaRuneCount, smallestSpellWordRuneCount, biggestSpellWordRuneCount int    // smallest and biggest creature names or formulas and words rune count
-)
+}
+
+// defaultValidator is the Validator of the package functions
+var defaultValidator = New()
+
+// New returns a Validator, it has to be initiated before it is used
+func New() *Validator {
+	return &Validator{}
+}
+
+// Default returns the Validator used by the package functions
+func Default() *Validator {
+	return defaultValidator
+}
 
-// Initiate initiates the validator, this should be called on the init() func
-func Initiate(TibiaDataUserAgent string) error {
+// Initiate initiates the validator, this should be called once on startup
+func (v *Validator) Initiate(TibiaDataUserAgent string) error {
 	// Make sure InitiateValidator can not be called concurrently
-	locker.Lock()
-	defer locker.Unlock()
+	v.locker.Lock()
+	defer v.locker.Unlock()
 
 	// Check if the validator has already been initiated
 	// as there is no need to initiate it twice
-	if initiated {
+	if v.initiated {
 		return ErrorAlreadyRunning
 	}
 
 	// Get the assets
 	tibiaMapping, err := tibiamapping.Run(TibiaDataUserAgent)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	// Load the assets into the validator
+	err = v.load(tibiaMapping)
+	if err != nil {
+		return err
+	}
+
+	// The validator is properly initiated
+	v.initiated = true
+
+	return nil
+}
+
+// Reload gets the assets again and replaces the data of the validator
+// the current data is kept if the assets can not be loaded
+func (v *Validator) Reload(TibiaDataUserAgent string) error {
+	// Get the assets, the validator keeps working meanwhile
+	tibiaMapping, err := tibiamapping.Run(TibiaDataUserAgent)
+	if err != nil {
+		return err
+	}
+
+	v.locker.Lock()
+	defer v.locker.Unlock()
+
+	// Check if the validator has been initiated
+	if !v.initiated {
+		return ErrorValidatorNotInitiated
 	}
 
+	return v.load(tibiaMapping)
+}
+
+// load sets the data of the validator to the assets of tibiaMapping, the lock must be held
+func (v *Validator) load(tibiaMapping *tibiamapping.TibiaMapping) error {
 	// Check if we got a nil struct
 	if tibiaMapping == nil {
 		return errors.New("tibia mapping struct is nil")
@@ -77,10 +126,10 @@ func Initiate(TibiaDataUserAgent string) error {
 	bytes := tibiaMapping.RawData
 
 	sha256Fields := strings.Fields(tibiaMapping.Sha256Sum)
-	sha256sum = sha256Fields[2]
-
 	sha512Fields := strings.Fields(tibiaMapping.Sha512Sum)
-	sha512sum = sha512Fields[2]
+	if len(sha256Fields) < 3 || len(sha512Fields) < 3 {
+		return errors.New("sha sum files are invalid")
+	}
 
 	// Check if the file is empty
 	if len(bytes) == 0 {
@@ -88,33 +137,42 @@ func Initiate(TibiaDataUserAgent string) error {
 	}
 
 	// Unmarshal the json bytes into a go struct
-	err = json.Unmarshal(bytes, &val)
+	var newVal validator
+	err := json.Unmarshal(bytes, &newVal)
 	if err != nil {
 		return err
 	}
 
-	// Set non changing vars
-	setVars()
+	// Check if the data can be used for the vars
+	if len(newVal.Creatures) == 0 || len(newVal.Spells) == 0 {
+		return errors.New("data.json file has no creatures or spells")
+	}
+
+	v.val = newVal
+	v.sha256sum = sha256Fields[2]
+	v.sha512sum = sha512Fields[2]
 
-	// The validator is properly initiated
-	initiated = true
+	// Set vars depending on the data
+	v.smallestCreatureName, v.biggestCreatureName = "", ""
+	v.smallestSpellNameOrFormula, v.biggestSpellNameOrFormula = "", ""
+	v.setVars()
 
 	return nil
 }
 
-func setVars() {
-	setCreaturesVars()
-	setSpellsVars()
+func (v *Validator) setVars() {
+	v.setCreaturesVars()
+	v.setSpellsVars()
 }
 
 // setCreaturesVars sets creatures vars
-// this only needs to be called once as it will never change during runtime
-func setCreaturesVars() {
-	if smallestCreatureName == "" {
-		smallestName := val.Creatures[0].Name
+// this only needs to be called when the data is loaded
+func (v *Validator) setCreaturesVars() {
+	if v.smallestCreatureName == "" {
+		smallestName := v.val.Creatures[0].Name
 		var smallestWord string
 
-		for _, creature := range val.Creatures {
+		for _, creature := range v.val.Creatures {
 			if utf8.RuneCountInString(creature.Name) < utf8.RuneCountInString(smallestName) {
 				smallestName = creature.Name
 			}
@@ -136,17 +194,17 @@ func setCreaturesVars() {
 			}
 		}
 
-		smallestCreatureName = smallestName
-		smallestCreatureNameRuneCount = utf8.RuneCountInString(smallestName)
-		smallestCreatureWord = smallestWord
-		smallestCreatureWordRuneCount = utf8.RuneCountInString(smallestWord)
+		v.smallestCreatureName = smallestName
+		v.smallestCreatureNameRuneCount = utf8.RuneCountInString(smallestName)
+		v.smallestCreatureWord = smallestWord
+		v.smallestCreatureWordRuneCount = utf8.RuneCountInString(smallestWord)
 	}
 
-	if biggestCreatureName == "" {
-		biggestName := val.Creatures[0].PluralName
+	if v.biggestCreatureName == "" {
+		biggestName := v.val.Creatures[0].PluralName
 		var biggestWord string
 
-		for _, creature := range val.Creatures {
+		for _, creature := range v.val.Creatures {
 			if utf8.RuneCountInString(creature.Name) > utf8.RuneCountInString(biggestName) {
 				biggestName = creature.PluralName
 			}
@@ -168,21 +226,21 @@ func setCreaturesVars() {
 			}
 		}
 
-		biggestCreatureName = biggestName
-		biggestCreatureNameRuneCount = utf8.RuneCountInString(biggestName)
-		biggestCreatureWord = biggestWord
-		biggestCreatureWordRuneCount = utf8.RuneCountInString(biggestWord)
+		v.biggestCreatureName = biggestName
+		v.biggestCreatureNameRuneCount = utf8.RuneCountInString(biggestName)
+		v.biggestCreatureWord = biggestWord
+		v.biggestCreatureWordRuneCount = utf8.RuneCountInString(biggestWord)
 	}
 }
 
 // setSpellsVarss sets spells vars
-// this only needs to be called once as it will never change during runtime
-func setSpellsVars() {
-	if smallestSpellNameOrFormula == "" {
-		smallestName := val.Spells[0].Name
+// this only needs to be called when the data is loaded
+func (v *Validator) setSpellsVars() {
+	if v.smallestSpellNameOrFormula == "" {
+		smallestName := v.val.Spells[0].Name
 		var smallestWord string
 
-		for _, spell := range val.Spells {
+		for _, spell := range v.val.Spells {
 			if len(spell.Name) < utf8.RuneCountInString(smallestName) {
 				smallestName = spell.Name
 			}
@@ -212,17 +270,17 @@ func setSpellsVars() {
 			}
 		}
 
-		smallestSpellNameOrFormula = smallestName
-		smallestSpellNameOrFormulaRuneCount = utf8.RuneCountInString(smallestName)
-		smallestSpellWord = smallestWord
-		smallestSpellWordRuneCount = utf8.RuneCountInString(smallestWord)
+		v.smallestSpellNameOrFormula = smallestName
+		v.smallestSpellNameOrFormulaRuneCount = utf8.RuneCountInString(smallestName)
+		v.smallestSpellWord = smallestWord
+		v.smallestSpellWordRuneCount = utf8.RuneCountInString(smallestWord)
 	}
 
-	if biggestSpellNameOrFormula == "" {
-		biggestName := val.Spells[0].Name
+	if v.biggestSpellNameOrFormula == "" {
+		biggestName := v.val.Spells[0].Name
 		var biggestWord string
 
-		for _, spell := range val.Spells {
+		for _, spell := range v.val.Spells {
 			if len(spell.Name) > utf8.RuneCountInString(biggestName) {
 				biggestName = spell.Name
 			}
@@ -256,9 +314,9 @@ func setSpellsVars() {
 			}
 		}
 
-		biggestSpellNameOrFormula = biggestName
-		biggestSpellNameOrFormulaRuneCount = utf8.RuneCountInString(biggestName)
-		biggestSpellWord = biggestWord
-		biggestSpellWordRuneCount = utf8.RuneCountInString(biggestWord)
+		v.biggestSpellNameOrFormula = biggestName
+		v.biggestSpellNameOrFormulaRuneCount = utf8.RuneCountInString(biggestName)
+		v.biggestSpellWord = biggestWord
+		v.biggestSpellWordRuneCount = utf8.RuneCountInString(biggestWord)
 	}
 }
@@ -9,7 +9,7 @@ import (
 )
 
 func TestRaceCondition(t *testing.T) {
-	if !initiated {
+	if !defaultValidator.initiated {
 		err := Initiate("TibiaData-API-Testing")
 		if err != nil {
 			t.Fatal(err)
@@ -34,6 +34,39 @@ func TestRaceCondition(t *testing.T) {
 	wg.Wait()
 }
 
+func TestReloadRaceCondition(t *testing.T) {
+	if !defaultValidator.initiated {
+		err := Initiate("TibiaData-API-Testing")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := HouseExistsInTown(i, "Carlin")
+			if err != nil {
+				panic(err)
+			}
+
+			_, _ = IsCreatureNameValid("demon")
+		}(i)
+	}
+
+	err := Reload("TibiaData-API-Testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+}
+
 func TestNameValidator(t *testing.T) {
 	names := []string{
 		"Torbjörn",
@@ -89,7 +122,7 @@ func TestNameValidator(t *testing.T) {
 }
 
 func TestCreatureValidator(t *testing.T) {
-	if !initiated {
+	if !defaultValidator.initiated {
 		err := Initiate("TibiaData-API-Testing")
 		if err != nil {
 			t.Fatal(err)
@@ -188,7 +221,7 @@ func TestCreatureValidator(t *testing.T) {
 }
 
 func TestSpellValidator(t *testing.T) {
-	if !initiated {
+	if !defaultValidator.initiated {
 		err := Initiate("TibiaData-API-Testing")
 		if err != nil {
 			t.Fatal(err)
@@ -349,7 +382,7 @@ func TestGuildValidator(t *testing.T) {
 }
 
 func TestValidationFuncs(t *testing.T) {
-	if !initiated {
+	if !defaultValidator.initiated {
 		err := Initiate("TibiaData-API-Testing")
 		if err != nil {
 			t.Fatal(err)
@@ -477,6 +510,9 @@ func TestErrors(t *testing.T) {
 		ErrorStringCanNotBeConvertedToInt: {
 			Code: 9001,
 		},
+		ErrorStringCanNotBeEncoded: {
+			Code: 9002,
+		},
 		ErrorCharacterNameEmpty: {
 			Code: 10001,
 		},
@@ -585,6 +621,30 @@ func TestErrors(t *testing.T) {
 		ErrorGuildWordTooSmall: {
 			Code: 14007,
 		},
+		ErrorAPIKeyRequired: {
+			Code: 15001,
+		},
+		ErrorAPIKeyInvalid: {
+			Code: 15002,
+		},
+		ErrorAPIKeyRouteNotAllowed: {
+			Code: 15003,
+		},
+		ErrorAPIKeyMinuteQuotaExceeded: {
+			Code: 15004,
+		},
+		ErrorAPIKeyDailyQuotaExceeded: {
+			Code: 15005,
+		},
+		ErrorRateLimitExceeded: {
+			Code: 15006,
+		},
+		ErrorAdminCachePurgeTargetMissing: {
+			Code: 15007,
+		},
+		ErrorAdminDebugValueInvalid: {
+			Code: 15008,
+		},
 		ErrorCharacterNotFound: {
 			Code: 20001,
 		},
@@ -600,6 +660,21 @@ func TestErrors(t *testing.T) {
 		ErrorMaintenanceMode: {
 			Code: 20005,
 		},
+		ErrorUpstreamThrottled: {
+			Code: 20006,
+		},
+		ErrorUpstreamUnavailable: {
+			Code: 20007,
+		},
+		ErrorUpstreamErrorPage: {
+			Code: 20008,
+		},
+		ErrorUpstreamPageNotFound: {
+			Code: 20009,
+		},
+		ErrorUpstreamUnknownLayout: {
+			Code: 20010,
+		},
 	}
 
 	for err, values := range errs {
@@ -610,7 +685,7 @@ func TestErrors(t *testing.T) {
 }
 
 func TestUtils(t *testing.T) {
-	if !initiated {
+	if !defaultValidator.initiated {
 		err := Initiate("TibiaData-API-Testing")
 		if err != nil {
 			t.Fatal(err)
@@ -825,7 +900,7 @@ func TestUtils(t *testing.T) {
 }
 
 func TestFake(t *testing.T) {
-	if !initiated {
+	if !defaultValidator.initiated {
 		err := Initiate("TibiaData-API-Testing")
 		if err != nil {
 			t.Fatal(err)
@@ -844,9 +919,9 @@ func TestFake(t *testing.T) {
 	assert.Equal(14, MaxRunesAllowedInAGuildNameWord)
 	assert.Equal(2, MinRunesAllowedInAGuildNameWord)
 
-	setVars()
-	setCreaturesVars()
-	setSpellsVars()
+	defaultValidator.setVars()
+	defaultValidator.setCreaturesVars()
+	defaultValidator.setSpellsVars()
 }
 
 func TestNewsIDValidator(t *testing.T) {
@@ -882,3 +957,27 @@ func TestVocationValidator(t *testing.T) {
 		t.Fatalf("Vocation sorcerers is being reported as invalid but should be valid, err: %s", err)
 	}
 }
+
+func TestValidatorInstances(t *testing.T) {
+	if !defaultValidator.initiated {
+		err := Initiate("TibiaData-API-Testing")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert := assert.New(t)
+
+	// a new validator has data of its own
+	validator := New()
+	_, err := validator.GetWorlds()
+	assert.ErrorIs(err, ErrorValidatorNotInitiated)
+
+	_, err = GetWorlds()
+	assert.NoError(err)
+	assert.Same(defaultValidator, Default())
+
+	assert.NoError(validator.Initiate("TibiaData-API-Testing"))
+	assert.ErrorIs(validator.Initiate("TibiaData-API-Testing"), ErrorAlreadyRunning)
+	assert.NotSame(defaultValidator, validator)
+}